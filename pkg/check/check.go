@@ -3,6 +3,7 @@ package check
 import (
 	"fmt"
 
+	"github.com/VirtusLab/go-extended/pkg/errors"
 	"github.com/VirtusLab/go-extended/pkg/matcher"
 )
 
@@ -20,6 +21,11 @@ func (e *InvalidJavaScriptIdentifier) Error() string {
 		e.text, javaScriptIdentifierPattern)
 }
 
+// Code returns errors.InvalidArgument
+func (e *InvalidJavaScriptIdentifier) Code() errors.Code {
+	return errors.InvalidArgument
+}
+
 // IsValidJavaScriptIdentifier checks if the given string is a valid JavaScript/JSON identifier
 func IsValidJavaScriptIdentifier(value string) error {
 	if !matcher.Must(javaScriptIdentifierPattern).Match(value) {
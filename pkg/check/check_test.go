@@ -3,6 +3,7 @@ package check
 import (
 	"testing"
 
+	"github.com/VirtusLab/go-extended/pkg/errors"
 	"github.com/VirtusLab/go-extended/pkg/test"
 	"github.com/stretchr/testify/assert"
 )
@@ -64,6 +65,7 @@ func TestIsValidJavaScriptIdentifier(t *testing.T) {
 				wantErr := "must be a valid JavaScript identifier, '' does not match pattern '^[a-zA-Z_$][a-zA-Z0-9_$]*$'"
 				err := IsValidJavaScriptIdentifier(value)
 				assert.EqualError(t, err, wantErr, tt.Name)
+				assert.Equal(t, errors.InvalidArgument, errors.CodeOf(err), tt.Name)
 			},
 		},
 		test.Test{
@@ -0,0 +1,155 @@
+package errors
+
+import (
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultStackDepth is the maximum number of frames captured by default
+const DefaultStackDepth = 32
+
+// StackConfig configures how the stack traces are captured by Callers and the error constructors
+type StackConfig struct {
+	// Depth is the maximum number of captured frames, DefaultStackDepth if not positive
+	Depth int
+	// Skip is the number of additional frames skipped, e.g. to hide helper functions creating errors
+	Skip int
+	// Disabled turns the capture off, the errors have empty stack traces
+	Disabled bool
+	// DisabledPackages turns the capture off for the errors created in the given packages
+	// and their sub packages, e.g. "github.com/VirtusLab/go-extended/pkg/files"
+	DisabledPackages []string
+	// SampleRate captures the whole stack for only one in SampleRate errors, the other errors
+	// get only the frame that created them; every stack is captured if SampleRate is less than 2
+	SampleRate int
+	// DedupeOnWrap captures only the frame that wrapped the error, if the wrapped error
+	// already has a stack trace, see Wrap, Wrapf and With
+	DedupeOnWrap bool
+	// Lazy stores only the raw program counters when an error is created, nothing is symbolized until
+	// the stack trace is formatted or marshalled. DisabledPackages is then applied when the stack trace is read,
+	// instead of looking up the package of every error when it is created; this is cheaper
+	// as long as most of the errors are not created in the disabled packages.
+	Lazy bool
+}
+
+var (
+	stackConfig  atomic.Value
+	stackSamples uint64
+)
+
+func init() {
+	stackConfig.Store(StackConfig{})
+}
+
+// SetStackConfig sets how the stack traces are captured from now on, it is safe for concurrent use
+func SetStackConfig(config StackConfig) {
+	config.DisabledPackages = append([]string(nil), config.DisabledPackages...)
+	stackConfig.Store(config)
+}
+
+// CurrentStackConfig returns the current stack capture configuration
+func CurrentStackConfig() StackConfig {
+	config := stackConfig.Load().(StackConfig)
+	config.DisabledPackages = append([]string(nil), config.DisabledPackages...)
+	return config
+}
+
+// CallersSkip gets a stack dump like Callers, skipping the given number of additional frames.
+// CallersSkip(0) is the same as Callers, CallersSkip(1) can be used by an error constructor
+// called from a helper function, to start the stack at the caller of the helper.
+func CallersSkip(skip int) *Stack {
+	return capture(2+skip, nil)
+}
+
+// wrapCallers gets a stack dump for an error wrapping cause, skipping wrapCallers and its caller
+func wrapCallers(cause error) *Stack {
+	return capture(2, cause)
+}
+
+// capture gets a stack dump according to the configuration, skip is the number of frames
+// skipped above capture, wrapping is the wrapped error if any
+func capture(skip int, wrapping error) *Stack {
+	config := stackConfig.Load().(StackConfig)
+	// runtime.Callers counts itself and capture
+	skip += 2 + config.Skip
+	if config.Disabled || (!config.Lazy && disabledPackage(config.DisabledPackages, skip)) {
+		return &Stack{}
+	}
+
+	pcs := make([]uintptr, config.depth(wrapping))
+	n := runtime.Callers(skip, pcs)
+	st := Stack(pcs[:n])
+	return &st
+}
+
+// depth returns the number of frames captured for an error wrapping the given error, if any
+func (config StackConfig) depth(wrapping error) int {
+	depth := config.Depth
+	if depth <= 0 {
+		depth = DefaultStackDepth
+	}
+	if config.DedupeOnWrap && wrapping != nil && hasStackTrace(wrapping) {
+		depth = 1
+	}
+	if config.SampleRate > 1 && atomic.AddUint64(&stackSamples, 1)%uint64(config.SampleRate) != 0 {
+		depth = 1
+	}
+	return depth
+}
+
+// disabledPackage reports whether the function of the frame at skip is in one of the packages
+func disabledPackage(packages []string, skip int) bool {
+	if len(packages) == 0 {
+		return false
+	}
+	var pcs [1]uintptr
+	// runtime.Callers counts itself and disabledPackage, but not capture
+	if runtime.Callers(skip+1, pcs[:]) == 0 {
+		return false
+	}
+	return inPackages(pcs[0], packages)
+}
+
+// inPackages reports whether the function of the program counter is in one of the packages or their sub packages
+func inPackages(pc uintptr, packages []string) bool {
+	if len(packages) == 0 {
+		return false
+	}
+	pkg := packageName(Frame(pc).name())
+	for _, disabled := range packages {
+		if pkg == disabled || strings.HasPrefix(pkg, disabled+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// packageName returns the package path of a function's name reported by func.Name()
+func packageName(name string) string {
+	i := strings.LastIndex(name, "/")
+	if j := strings.Index(name[i+1:], "."); j >= 0 {
+		return name[:i+1+j]
+	}
+	return name
+}
+
+// hasStackTrace reports whether any error in the chain of err has a stack trace
+func hasStackTrace(err error) bool {
+	for err != nil {
+		if _, ok := err.(WithStackTrace); ok {
+			return true
+		}
+		wrapped := unwrap(err)
+		if len(wrapped) != 1 {
+			for _, e := range wrapped {
+				if hasStackTrace(e) {
+					return true
+				}
+			}
+			return false
+		}
+		err = wrapped[0]
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+func withStackConfig(t testing.TB, config StackConfig) {
+	previous := CurrentStackConfig()
+	SetStackConfig(config)
+	t.Cleanup(func() { SetStackConfig(previous) })
+}
+
+func newSkipped(skip int) error {
+	return &tracedError{message: "skipped", stack: CallersSkip(skip)}
+}
+
+func firstFunction(err error) string {
+	st := err.(WithStackTrace).StackTrace()
+	if len(st) == 0 {
+		return ""
+	}
+	return st[0].name()
+}
+
+func TestStackConfigDepth(t *testing.T) {
+	withStackConfig(t, StackConfig{Depth: 2})
+	if got := len(New("shallow").(WithStackTrace).StackTrace()); got != 2 {
+		t.Errorf("Depth 2: got %d frames, want 2", got)
+	}
+	SetStackConfig(StackConfig{})
+	if got := len(New("default").(WithStackTrace).StackTrace()); got < 2 || got > DefaultStackDepth {
+		t.Errorf("default depth: got %d frames", got)
+	}
+}
+
+func TestStackConfigSkip(t *testing.T) {
+	withStackConfig(t, StackConfig{})
+	want := "github.com/VirtusLab/go-extended/pkg/errors.TestStackConfigSkip"
+	if got := firstFunction(newSkipped(0)); got != want {
+		t.Errorf("CallersSkip(0): got %s, want %s", got, want)
+	}
+	helper := func() error { return newSkipped(1) }
+	if got := firstFunction(helper()); got != want {
+		t.Errorf("CallersSkip(1): got %s, want %s", got, want)
+	}
+
+	SetStackConfig(StackConfig{Skip: 1})
+	helper = func() error { return New("helper") }
+	if got := firstFunction(helper()); got != want {
+		t.Errorf("Skip 1: got %s, want %s", got, want)
+	}
+}
+
+func TestStackConfigDisabled(t *testing.T) {
+	withStackConfig(t, StackConfig{Disabled: true})
+	err := Wrapf(New("whoops"), "wrapped")
+	if got := len(err.(WithStackTrace).StackTrace()); got != 0 {
+		t.Errorf("Disabled: got %d frames, want 0", got)
+	}
+	if got, want := fmt.Sprintf("%+v", err), "whoops\nwrapped"; got != want {
+		t.Errorf("Disabled %%+v: got %q, want %q", got, want)
+	}
+
+	SetStackConfig(StackConfig{DisabledPackages: []string{"github.com/VirtusLab/go-extended/pkg/errors"}})
+	if got := len(New("whoops").(WithStackTrace).StackTrace()); got != 0 {
+		t.Errorf("DisabledPackages: got %d frames, want 0", got)
+	}
+	SetStackConfig(StackConfig{DisabledPackages: []string{"github.com/VirtusLab/go-extended/pkg/err", "github.com/VirtusLab"}})
+	if got := len(New("whoops").(WithStackTrace).StackTrace()); got != 0 {
+		t.Errorf("DisabledPackages with a parent package: got %d frames, want 0", got)
+	}
+	SetStackConfig(StackConfig{DisabledPackages: []string{"github.com/VirtusLab/go-extended/pkg/err"}})
+	if got := len(New("whoops").(WithStackTrace).StackTrace()); got == 0 {
+		t.Errorf("DisabledPackages with a package name prefix: got no frames")
+	}
+}
+
+func TestStackConfigSampleRate(t *testing.T) {
+	withStackConfig(t, StackConfig{SampleRate: 3})
+	var full, single int
+	for i := 0; i < 9; i++ {
+		err := New("sampled")
+		switch len(err.(WithStackTrace).StackTrace()) {
+		case 1:
+			single++
+			if got, want := firstFunction(err), "github.com/VirtusLab/go-extended/pkg/errors.TestStackConfigSampleRate"; got != want {
+				t.Errorf("sampled out: got %s, want %s", got, want)
+			}
+		default:
+			full++
+		}
+	}
+	if full != 3 || single != 6 {
+		t.Errorf("SampleRate 3: got %d full and %d single frame stacks, want 3 and 6", full, single)
+	}
+}
+
+func TestStackConfigDedupeOnWrap(t *testing.T) {
+	withStackConfig(t, StackConfig{DedupeOnWrap: true})
+	err := New("whoops")
+	for _, wrapped := range []error{Wrap(err), Wrapf(err, "wrapped"), With(err, "key", 1), NotFound.Wrap(fmt.Errorf("chain: %w", err))} {
+		st := wrapped.(WithStackTrace).StackTrace()
+		if len(st) != 1 || st[0].name() != "github.com/VirtusLab/go-extended/pkg/errors.TestStackConfigDedupeOnWrap" {
+			t.Errorf("DedupeOnWrap of %v: got %v, want only the wrapping frame", wrapped, st)
+		}
+	}
+	if got := len(Wrap(io.EOF).(WithStackTrace).StackTrace()); got < 2 {
+		t.Errorf("DedupeOnWrap of io.EOF: got %d frames, want the whole stack", got)
+	}
+}
+
+func TestStackConfigLazy(t *testing.T) {
+	withStackConfig(t, StackConfig{Lazy: true, DisabledPackages: []string{"github.com/VirtusLab/go-extended/pkg/errors"}})
+	err := New("whoops")
+	if got := len(*err.(*tracedError).stack); got == 0 {
+		t.Errorf("Lazy: got no program counters, want them stored")
+	}
+	if got := len(err.(WithStackTrace).StackTrace()); got != 0 {
+		t.Errorf("Lazy in a disabled package: got %d frames, want 0", got)
+	}
+	if got, want := fmt.Sprintf("%+v", err), "whoops"; got != want {
+		t.Errorf("Lazy in a disabled package %%+v: got %q, want %q", got, want)
+	}
+
+	SetStackConfig(StackConfig{Lazy: true, DisabledPackages: []string{"github.com/VirtusLab/go-extended/pkg/files"}})
+	if got, want := firstFunction(New("whoops")), "github.com/VirtusLab/go-extended/pkg/errors.TestStackConfigLazy"; got != want {
+		t.Errorf("Lazy: got %s, want %s", got, want)
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	tests := map[string]string{
+		"github.com/VirtusLab/go-extended/pkg/errors.(*tracedError).Error": "github.com/VirtusLab/go-extended/pkg/errors",
+		"github.com/VirtusLab/go-extended/pkg/errors.New":                  "github.com/VirtusLab/go-extended/pkg/errors",
+		"main.main":    "main",
+		"runtime.main": "runtime",
+		"unknown":      "unknown",
+	}
+	for name, want := range tests {
+		if got := packageName(name); got != want {
+			t.Errorf("packageName(%q): got %q, want %q", name, got, want)
+		}
+	}
+}
+
+func deepNew(depth int) error {
+	if depth == 0 {
+		return New("deep")
+	}
+	return deepNew(depth - 1)
+}
+
+func benchmarkNew(b *testing.B, config StackConfig) {
+	withStackConfig(b, config)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = deepNew(16)
+	}
+}
+
+func BenchmarkNewDefault(b *testing.B)  { benchmarkNew(b, StackConfig{}) }
+func BenchmarkNewDepth4(b *testing.B)   { benchmarkNew(b, StackConfig{Depth: 4}) }
+func BenchmarkNewDepth64(b *testing.B)  { benchmarkNew(b, StackConfig{Depth: 64}) }
+func BenchmarkNewSampled(b *testing.B)  { benchmarkNew(b, StackConfig{SampleRate: 100}) }
+func BenchmarkNewDisabled(b *testing.B) { benchmarkNew(b, StackConfig{Disabled: true}) }
+func BenchmarkNewDisabledPackage(b *testing.B) {
+	benchmarkNew(b, StackConfig{DisabledPackages: []string{"github.com/VirtusLab/go-extended/pkg/errors"}})
+}
+
+// the errors are created outside of the disabled packages, the lazy capture skips looking up their package
+func BenchmarkNewOtherDisabledPackage(b *testing.B) {
+	benchmarkNew(b, StackConfig{DisabledPackages: []string{"github.com/VirtusLab/go-extended/pkg/files"}})
+}
+func BenchmarkNewOtherDisabledPackageLazy(b *testing.B) {
+	benchmarkNew(b, StackConfig{Lazy: true, DisabledPackages: []string{"github.com/VirtusLab/go-extended/pkg/files"}})
+}
+
+func benchmarkWrap(b *testing.B, config StackConfig) {
+	withStackConfig(b, config)
+	err := New("whoops")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = Wrapf(err, "wrapped")
+	}
+}
+
+func BenchmarkWrapDefault(b *testing.B) { benchmarkWrap(b, StackConfig{}) }
+func BenchmarkWrapDedupe(b *testing.B)  { benchmarkWrap(b, StackConfig{DedupeOnWrap: true}) }
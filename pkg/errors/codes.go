@@ -0,0 +1,205 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Code classifies an error, e.g. to map it to a process exit code or a HTTP status
+type Code int
+
+// The error codes, Unknown is used when an error has no code
+const (
+	Unknown Code = iota
+	Internal
+	InvalidArgument
+	NotFound
+	AlreadyExists
+	Conflict
+	PermissionDenied
+	Timeout
+	Canceled
+	Unavailable
+	Unimplemented
+)
+
+var codeNames = map[Code]string{
+	Unknown:          "Unknown",
+	Internal:         "Internal",
+	InvalidArgument:  "InvalidArgument",
+	NotFound:         "NotFound",
+	AlreadyExists:    "AlreadyExists",
+	Conflict:         "Conflict",
+	PermissionDenied: "PermissionDenied",
+	Timeout:          "Timeout",
+	Canceled:         "Canceled",
+	Unavailable:      "Unavailable",
+	Unimplemented:    "Unimplemented",
+}
+
+// WithCode represents an error with a code
+type WithCode interface {
+	// Code returns the code of this error, Unknown if it has none
+	Code() Code
+}
+
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Code(%d)", int(c))
+}
+
+// MarshalText implements encoding.TextMarshaler, the code is written as its name
+func (c Code) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, an unknown name is read as Unknown
+func (c *Code) UnmarshalText(text []byte) error {
+	*c = ParseCode(string(text))
+	return nil
+}
+
+// ParseCode returns the code with the given name, or Unknown if there is none
+func ParseCode(name string) Code {
+	for code, codeName := range codeNames {
+		if codeName == name {
+			return code
+		}
+	}
+	return Unknown
+}
+
+// New returns a new error with the code, the supplied message and a stack trace.
+// It records the stack trace at the point it was called.
+func (c Code) New(message string) error {
+	return &tracedError{
+		message: message,
+		code:    c,
+		stack:   Callers(),
+	}
+}
+
+// Errorf returns a new error with the code, the supplied formatted message and a stack trace.
+// It records the stack trace at the point it was called.
+func (c Code) Errorf(format string, args ...interface{}) error {
+	return &tracedError{
+		cause: fmt.Errorf(format, args...),
+		code:  c,
+		stack: Callers(),
+	}
+}
+
+// Wrap wraps a given error with the code and a stack trace.
+// It records the stack trace at the point it was called.
+func (c Code) Wrap(e error) error {
+	if e == nil {
+		return nil
+	}
+	return &tracedError{
+		cause: e,
+		code:  c,
+		stack: wrapCallers(e),
+	}
+}
+
+// Wrapf wraps a given error with the code, a formatted message and a stack trace.
+// It records the stack trace at the point it was called.
+func (c Code) Wrapf(e error, format string, args ...interface{}) error {
+	if e == nil {
+		return nil
+	}
+	return &tracedError{
+		cause:   e,
+		message: fmt.Sprintf(format, args...),
+		code:    c,
+		stack:   wrapCallers(e),
+	}
+}
+
+// Code returns the code of this error, Unknown if it has none
+func (t *tracedError) Code() Code {
+	return t.code
+}
+
+// CodeOf returns the first code found along the chain of err, followed using both Unwrap and Cause.
+// If there is none, the well known standard errors are classified, e.g. os.ErrNotExist is NotFound
+// and context.DeadlineExceeded is Timeout. Otherwise CodeOf returns Unknown.
+func CodeOf(err error) Code {
+	if err == nil {
+		return Unknown
+	}
+	if code := codeOf(err); code != Unknown {
+		return code
+	}
+	switch {
+	case Is(err, context.DeadlineExceeded):
+		return Timeout
+	case Is(err, context.Canceled):
+		return Canceled
+	case Is(err, os.ErrNotExist):
+		return NotFound
+	case Is(err, os.ErrExist):
+		return AlreadyExists
+	case Is(err, os.ErrPermission):
+		return PermissionDenied
+	}
+	return Unknown
+}
+
+func codeOf(err error) Code {
+	for err != nil {
+		if withCode, ok := err.(WithCode); ok {
+			if code := withCode.Code(); code != Unknown {
+				return code
+			}
+		}
+		wrapped := unwrap(err)
+		if len(wrapped) != 1 {
+			for _, e := range wrapped {
+				if code := codeOf(e); code != Unknown {
+					return code
+				}
+			}
+			return Unknown
+		}
+		err = wrapped[0]
+	}
+	return Unknown
+}
+
+// ExitCodes maps the error codes to process exit codes
+type ExitCodes map[Code]int
+
+// DefaultExitCodes is the mapping used by ExitCode, based on sysexits.h and the timeout command,
+// it can be changed to configure the exit codes of a CLI
+var DefaultExitCodes = ExitCodes{
+	InvalidArgument:  64,
+	NotFound:         66,
+	Unavailable:      69,
+	Internal:         70,
+	AlreadyExists:    73,
+	Conflict:         75,
+	PermissionDenied: 77,
+	Timeout:          124,
+	Canceled:         130,
+}
+
+// ExitCode returns the process exit code for err: 0 for nil, the mapped exit code for the code of err,
+// or 1 if the code is not mapped
+func (m ExitCodes) ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if exitCode, ok := m[CodeOf(err)]; ok {
+		return exitCode
+	}
+	return 1
+}
+
+// ExitCode returns the process exit code for err using DefaultExitCodes
+func ExitCode(err error) int {
+	return DefaultExitCodes.ExitCode(err)
+}
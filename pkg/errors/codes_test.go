@@ -0,0 +1,106 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	_, openErr := os.Open("does-not-exist")
+	tests := []struct {
+		err  error
+		want Code
+	}{
+		{nil, Unknown},
+		{io.EOF, Unknown},
+		{New("no code"), Unknown},
+		{NotFound.New("template missing"), NotFound},
+		{InvalidArgument.Errorf("bad value %d", 3), InvalidArgument},
+		{Conflict.Wrap(io.EOF), Conflict},
+		{Wrapf(Timeout.Wrapf(io.EOF, "read"), "load"), Timeout},
+		{fmt.Errorf("load: %w", With(Internal.New("boom"), "path", "x")), Internal},
+		{Unavailable.Wrap(NotFound.New("inner")), Unavailable},
+		{Wrap(Unknown.Wrap(NotFound.New("inner"))), NotFound},
+		{Join(io.EOF, PermissionDenied.New("denied")), PermissionDenied},
+		{Wrapf(openErr, "open"), NotFound},
+		{Wrap(context.DeadlineExceeded), Timeout},
+		{fmt.Errorf("stopped: %w", context.Canceled), Canceled},
+		{InvalidArgument.Wrap(openErr), InvalidArgument},
+	}
+	for i, tt := range tests {
+		if got := CodeOf(tt.err); got != tt.want {
+			t.Errorf("test %d: CodeOf(%v): got %v, want %v", i+1, tt.err, got, tt.want)
+		}
+	}
+
+	if Conflict.Wrap(nil) != nil || Conflict.Wrapf(nil, "nil") != nil {
+		t.Errorf("wrapping nil with a code: expected nil")
+	}
+	if got, want := NotFound.Wrapf(io.EOF, "read %s", "config").Error(), "read config: EOF"; got != want {
+		t.Errorf("Wrapf.Error(): got %q, want %q", got, want)
+	}
+}
+
+func TestCodeString(t *testing.T) {
+	for code := Unknown; code <= Unimplemented; code++ {
+		if got := ParseCode(code.String()); got != code {
+			t.Errorf("ParseCode(%q): got %v, want %v", code.String(), got, code)
+		}
+	}
+	if got, want := Code(42).String(), "Code(42)"; got != want {
+		t.Errorf("String(): got %q, want %q", got, want)
+	}
+	if got := ParseCode("NoSuchCode"); got != Unknown {
+		t.Errorf("ParseCode(\"NoSuchCode\"): got %v, want Unknown", got)
+	}
+
+	data, err := json.Marshal(map[string]Code{"code": NotFound})
+	if err != nil || string(data) != `{"code":"NotFound"}` {
+		t.Errorf("json.Marshal: got %s, %v", data, err)
+	}
+	var decoded map[string]Code
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded["code"] != NotFound {
+		t.Errorf("json.Unmarshal: got %v, %v", decoded, err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if got := ExitCode(nil); got != 0 {
+		t.Errorf("ExitCode(nil): got %d, want 0", got)
+	}
+	if got := ExitCode(io.EOF); got != 1 {
+		t.Errorf("ExitCode(io.EOF): got %d, want 1", got)
+	}
+	if got := ExitCode(Wrap(InvalidArgument.New("bad flag"))); got != 64 {
+		t.Errorf("ExitCode(InvalidArgument): got %d, want 64", got)
+	}
+	if got := ExitCode(Wrap(context.DeadlineExceeded)); got != 124 {
+		t.Errorf("ExitCode(context.DeadlineExceeded): got %d, want 124", got)
+	}
+
+	custom := ExitCodes{NotFound: 3}
+	if got := custom.ExitCode(NotFound.New("missing")); got != 3 {
+		t.Errorf("custom ExitCode(NotFound): got %d, want 3", got)
+	}
+	if got := custom.ExitCode(InvalidArgument.New("bad flag")); got != 1 {
+		t.Errorf("custom ExitCode(InvalidArgument): got %d, want 1", got)
+	}
+}
+
+func TestCodeJSON(t *testing.T) {
+	data, err := MarshalJSON(Wrapf(Conflict.New("version mismatch"), "update"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := UnmarshalJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := CodeOf(decoded); got != Conflict {
+		t.Errorf("CodeOf(decoded): got %v, want Conflict, from %s", got, data)
+	}
+}
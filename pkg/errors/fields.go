@@ -0,0 +1,117 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WithFields represents an error with structured key/value fields
+type WithFields interface {
+	// Fields returns the fields attached to this error, without the fields of the wrapped errors
+	Fields() map[string]interface{}
+}
+
+// Field is a key/value pair attached to an error, see With
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// With wraps a given error with structured fields given as alternating keys and values,
+// e.g. With(err, "path", path, "attempt", 3). A key without a value gets a nil value,
+// a key that is not a string is formatted with fmt.Sprint.
+// It records the stack trace at the point it was called.
+func With(e error, keysAndValues ...interface{}) error {
+	if e == nil {
+		return nil
+	}
+	return &tracedError{
+		cause:  e,
+		fields: toFields(keysAndValues),
+		stack:  wrapCallers(e),
+	}
+}
+
+func toFields(keysAndValues []interface{}) []Field {
+	fields := make([]Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		var value interface{}
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+		fields = append(fields, Field{Key: key, Value: value})
+	}
+	return fields
+}
+
+// Fields returns every field attached along the chain of err, followed using both Unwrap and Cause.
+// If a key is attached more than once, the value closest to err wins.
+// Fields returns an empty map if there are no fields.
+func Fields(err error) map[string]interface{} {
+	fields := make(map[string]interface{})
+	collectFields(err, fields)
+	return fields
+}
+
+func collectFields(err error, fields map[string]interface{}) {
+	for err != nil {
+		if withFields, ok := err.(WithFields); ok {
+			for key, value := range withFields.Fields() {
+				if _, ok := fields[key]; !ok {
+					fields[key] = value
+				}
+			}
+		}
+		wrapped := unwrap(err)
+		if len(wrapped) != 1 {
+			for _, e := range wrapped {
+				collectFields(e, fields)
+			}
+			return
+		}
+		err = wrapped[0]
+	}
+}
+
+// Fields returns the fields attached to this error
+func (t *tracedError) Fields() map[string]interface{} {
+	fields := make(map[string]interface{}, len(t.fields))
+	for _, field := range t.fields {
+		fields[field.Key] = field.Value
+	}
+	return fields
+}
+
+// formatFields returns the fields as key=value pairs, in order of attaching,
+// the values are quoted if needed
+func formatFields(fields []Field) string {
+	pairs := make([]string, len(fields))
+	for i, field := range fields {
+		value := fmt.Sprint(field.Value)
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		pairs[i] = field.Key + "=" + value
+	}
+	return strings.Join(pairs, " ")
+}
+
+// formatFieldMap returns the fields as key=value pairs, sorted by key
+func formatFieldMap(fields map[string]interface{}) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	sorted := make([]Field, len(keys))
+	for i, key := range keys {
+		sorted[i] = Field{Key: key, Value: fields[key]}
+	}
+	return formatFields(sorted)
+}
@@ -0,0 +1,64 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithNil(t *testing.T) {
+	if got := With(nil, "key", "value"); got != nil {
+		t.Errorf("With(nil, \"key\", \"value\"): got %#v, expected nil", got)
+	}
+}
+
+func TestWith(t *testing.T) {
+	err := With(io.EOF, "path", "/tmp/config.yaml", "attempt", 3)
+	if got, want := err.Error(), "EOF"; got != want {
+		t.Errorf("With.Error(): got %q, want %q", got, want)
+	}
+	if Cause(err) != io.EOF || !Is(err, io.EOF) {
+		t.Errorf("With(io.EOF): expected io.EOF to be the cause")
+	}
+	if got, want := Fields(err), map[string]interface{}{"path": "/tmp/config.yaml", "attempt": 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields(): got %v, want %v", got, want)
+	}
+
+	if got, want := Fields(With(io.EOF, "odd", 1, "dangling")), map[string]interface{}{"odd": 1, "dangling": nil}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields() with a dangling key: got %v, want %v", got, want)
+	}
+	if got, want := Fields(With(io.EOF, 42, "answer")), map[string]interface{}{"42": "answer"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields() with a non-string key: got %v, want %v", got, want)
+	}
+	if got := Fields(io.EOF); len(got) != 0 {
+		t.Errorf("Fields(io.EOF): got %v, want no fields", got)
+	}
+}
+
+func TestFieldsChain(t *testing.T) {
+	err := With(io.EOF, "path", "inner", "attempt", 1)
+	err = Wrapf(err, "read")
+	err = fmt.Errorf("load: %w", With(err, "attempt", 3, "template", "main"))
+	err = Join(err, With(New("other"), "path", "other", "extra", true))
+
+	want := map[string]interface{}{"path": "inner", "attempt": 3, "template": "main", "extra": true}
+	if got := Fields(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields(): got %v, want %v", got, want)
+	}
+}
+
+func TestWithFormat(t *testing.T) {
+	err := With(Wrapf(io.EOF, "read"), "path", "/tmp/my config.yaml", "attempt", 3, "empty", "")
+	if got, want := fmt.Sprintf("%v", err), "read: EOF"; got != want {
+		t.Errorf("%%v: got %q, want %q", got, want)
+	}
+	testFormatRegexp(t, 0, err, "%+v", "EOF\n"+
+		"read\n"+
+		"github.com/VirtusLab/go-extended/pkg/errors.TestWithFormat\n"+
+		"\t.+/pkg/errors/fields_test.go:\\d+")
+	if got, want := fmt.Sprintf("%+v", err), "\npath=\"/tmp/my config.yaml\" attempt=3 empty=\"\"\n"; !strings.Contains(got, want) {
+		t.Errorf("%%+v: got %q, want the fields %q", got, want)
+	}
+}
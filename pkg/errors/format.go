@@ -22,6 +22,8 @@ type tracedError struct {
 	cause   error
 	stack   *Stack
 	message string
+	fields  []Field
+	code    Code
 }
 
 // Wrapf wraps a given error with a formatted message and a stack trace.
@@ -34,7 +36,7 @@ func Wrapf(e error, format string, args ...interface{}) error {
 	return &tracedError{
 		cause:   e,
 		message: fmt.Sprintf(format, args...),
-		stack:   Callers(),
+		stack:   wrapCallers(e),
 	}
 }
 
@@ -48,7 +50,7 @@ func Wrap(e error) error {
 	return &tracedError{
 		cause:   e,
 		message: "",
-		stack:   Callers(),
+		stack:   wrapCallers(e),
 	}
 }
 
@@ -89,14 +91,25 @@ func (t *tracedError) Cause() error {
 	return t.cause
 }
 
+// Unwrap returns the wrapped error, the same as Cause, see also Is and As
+func (t *tracedError) Unwrap() error {
+	return t.cause
+}
+
 func (t *tracedError) StackTrace() StackTrace {
 	return t.stack.StackTrace()
 }
 
 // Format implements fmt.Formatter used by Sprint(f) or Fprint(f) etc.
+// Under %+v every error of the chain is printed with its stack trace,
+// under %#v the message is printed with the merged stack trace of the chain, see MergedStackTrace.
 func (t *tracedError) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
+		if s.Flag('#') {
+			_, _ = io.WriteString(s, stackFormat.Load().(StackFormat).Sprint(t))
+			return
+		}
 		if s.Flag('+') {
 			hasMessage := len(t.message) > 0
 			hasCause := t.cause != nil
@@ -108,6 +121,9 @@ func (t *tracedError) Format(s fmt.State, verb rune) {
 			} else if !hasMessage && hasCause {
 				_, _ = fmt.Fprintf(s, "%+v", t.cause)
 			}
+			if len(t.fields) > 0 {
+				_, _ = fmt.Fprintf(s, "\n%s", formatFields(t.fields))
+			}
 			t.stack.Format(s, verb)
 			return
 		}
@@ -147,6 +163,10 @@ func Cause(err error) error {
 // FormatCauseAndStack helps to implement fmt.Formatter used by Sprint(f) or Fprint(f) etc.
 // Use for custom error implementations with Cause and StackFormatter
 func FormatCauseAndStack(err error, f *Stack, s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		_, _ = io.WriteString(s, stackFormat.Load().(StackFormat).Sprint(err))
+		return
+	}
 	_, _ = io.WriteString(s, err.Error())
 	f.Format(s, verb)
 }
@@ -0,0 +1,289 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DecodedFrame is a stack frame read back from JSON, see MarshalJSON
+type DecodedFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// DecodedError is an error chain read back from JSON, e.g. received from another process, see UnmarshalJSON.
+// Every layer of the original chain becomes a DecodedError, the wrapped layers are in Causes.
+type DecodedError struct {
+	// Message is the message added by this layer, without the messages of the wrapped layers
+	Message string
+	// Type is the Go type of the original error, e.g. *os.PathError
+	Type string
+	// ErrorCode is the code of this layer, see CodeOf
+	ErrorCode Code
+	// FieldValues are the structured fields attached to this layer, see With
+	FieldValues map[string]interface{}
+	// Stack is the stack trace of this layer, if it had one
+	Stack []DecodedFrame
+	// Causes are the wrapped layers
+	Causes []*DecodedError
+
+	// text is the whole original message, only if it can't be rebuilt from Message and Causes
+	text string
+}
+
+// jsonError is the JSON representation of a single layer of an error chain
+type jsonError struct {
+	Message string                 `json:"message"`
+	Error   string                 `json:"error,omitempty"`
+	Type    string                 `json:"type"`
+	Code    Code                   `json:"code,omitempty"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+	Stack   []DecodedFrame         `json:"stack,omitempty"`
+	Causes  []*jsonError           `json:"causes,omitempty"`
+}
+
+// MarshalJSON returns the JSON representation of the whole chain of err, followed using both Unwrap and Cause.
+// Every layer holds its message, Go type, code, structured fields and stack frames, the wrapped layers are in "causes".
+// Use UnmarshalJSON to read it back.
+func MarshalJSON(err error) ([]byte, error) {
+	if err == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(toJSONError(err))
+}
+
+// MarshalJSON implements json.Marshaler, see MarshalJSON
+func (t *tracedError) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(t)
+}
+
+func toJSONError(err error) *jsonError {
+	if decoded, ok := err.(*DecodedError); ok {
+		return decoded.toJSONError()
+	}
+
+	layer := &jsonError{
+		Type: fmt.Sprintf("%T", err),
+	}
+	for _, cause := range unwrap(err) {
+		if cause != nil {
+			layer.Causes = append(layer.Causes, toJSONError(cause))
+		}
+	}
+
+	text := err.Error()
+	if traced, ok := err.(*tracedError); ok {
+		layer.Message = traced.message
+	} else {
+		layer.Message = ownMessage(text, err)
+	}
+	if rebuildMessage(layer.Message, causeErrors(err)) != text {
+		layer.Error = text
+	}
+
+	if withCode, ok := err.(WithCode); ok {
+		layer.Code = withCode.Code()
+	}
+	if withFields, ok := err.(WithFields); ok {
+		for key, value := range withFields.Fields() {
+			if layer.Fields == nil {
+				layer.Fields = make(map[string]interface{})
+			}
+			layer.Fields[key] = jsonValue(value)
+		}
+	}
+	if withStack, ok := err.(WithStackTrace); ok {
+		for _, frame := range withStack.StackTrace() {
+			layer.Stack = append(layer.Stack, DecodedFrame{
+				Function: frame.name(),
+				File:     frame.file(),
+				Line:     frame.line(),
+			})
+		}
+	}
+	return layer
+}
+
+// ownMessage returns the part of text added by err, assuming the usual "message: cause" convention
+func ownMessage(text string, err error) string {
+	causes := causeErrors(err)
+	if len(causes) == 0 {
+		return text
+	}
+	causeText := rebuildMessage("", causes)
+	if text == causeText {
+		return ""
+	}
+	if strings.HasSuffix(text, ": "+causeText) {
+		return strings.TrimSuffix(text, ": "+causeText)
+	}
+	return text
+}
+
+func causeErrors(err error) []error {
+	var causes []error
+	for _, cause := range unwrap(err) {
+		if cause != nil {
+			causes = append(causes, cause)
+		}
+	}
+	return causes
+}
+
+// rebuildMessage joins the message with the messages of the causes, the same way tracedError and Join do
+func rebuildMessage(message string, causes []error) string {
+	messages := make([]string, len(causes))
+	for i, cause := range causes {
+		messages[i] = cause.Error()
+	}
+	causeText := strings.Join(messages, "\n")
+	switch {
+	case message == "":
+		return causeText
+	case causeText == "":
+		return message
+	default:
+		return message + ": " + causeText
+	}
+}
+
+// jsonValue returns value if it can be represented as JSON, or its text otherwise
+func jsonValue(value interface{}) interface{} {
+	if _, err := json.Marshal(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	return value
+}
+
+// UnmarshalJSON reads back an error chain written by MarshalJSON.
+// The returned error has the same message as the original one, and exposes the original
+// layers, fields and stack frames, see DecodedError.
+func UnmarshalJSON(data []byte) (*DecodedError, error) {
+	decoded := &DecodedError{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalJSON
+func (d *DecodedError) UnmarshalJSON(data []byte) error {
+	var layer jsonError
+	if err := json.Unmarshal(data, &layer); err != nil {
+		return Wrapf(err, "can't decode an error chain")
+	}
+	*d = *fromJSONError(&layer)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, see MarshalJSON
+func (d *DecodedError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.toJSONError())
+}
+
+func fromJSONError(layer *jsonError) *DecodedError {
+	decoded := &DecodedError{
+		Message:     layer.Message,
+		Type:        layer.Type,
+		ErrorCode:   layer.Code,
+		FieldValues: layer.Fields,
+		Stack:       layer.Stack,
+		text:        layer.Error,
+	}
+	for _, cause := range layer.Causes {
+		if cause != nil {
+			decoded.Causes = append(decoded.Causes, fromJSONError(cause))
+		}
+	}
+	return decoded
+}
+
+func (d *DecodedError) toJSONError() *jsonError {
+	layer := &jsonError{
+		Message: d.Message,
+		Error:   d.text,
+		Type:    d.Type,
+		Code:    d.ErrorCode,
+		Fields:  d.FieldValues,
+		Stack:   d.Stack,
+	}
+	for _, cause := range d.Causes {
+		layer.Causes = append(layer.Causes, cause.toJSONError())
+	}
+	return layer
+}
+
+func (d *DecodedError) Error() string {
+	if d.text != "" {
+		return d.text
+	}
+	return rebuildMessage(d.Message, d.Unwrap())
+}
+
+// Unwrap returns the wrapped layers, see Is and As
+func (d *DecodedError) Unwrap() []error {
+	causes := make([]error, len(d.Causes))
+	for i, cause := range d.Causes {
+		causes[i] = cause
+	}
+	return causes
+}
+
+// Cause returns the wrapped layer, or nil if there is none or many
+func (d *DecodedError) Cause() error {
+	if len(d.Causes) != 1 {
+		return nil
+	}
+	return d.Causes[0]
+}
+
+// Code returns the code of this layer, see CodeOf
+func (d *DecodedError) Code() Code {
+	return d.ErrorCode
+}
+
+// Fields returns the fields attached to this layer, see Fields
+func (d *DecodedError) Fields() map[string]interface{} {
+	return d.FieldValues
+}
+
+// Format implements fmt.Formatter used by Sprint(f) or Fprint(f) etc.
+// Under %+v the layers are printed like the original chain, with the fields and the stack frames.
+func (d *DecodedError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			for i, cause := range d.Causes {
+				if i > 0 {
+					_, _ = io.WriteString(s, "\n")
+				}
+				_, _ = fmt.Fprintf(s, "%+v", cause)
+			}
+			message := d.Message
+			if d.text != "" {
+				message = d.text
+			}
+			if message != "" {
+				if len(d.Causes) > 0 {
+					_, _ = io.WriteString(s, "\n")
+				}
+				_, _ = io.WriteString(s, message)
+			}
+			if len(d.FieldValues) > 0 {
+				_, _ = fmt.Fprintf(s, "\n%s", formatFieldMap(d.FieldValues))
+			}
+			for _, frame := range d.Stack {
+				_, _ = fmt.Fprintf(s, "\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, d.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", d.Error())
+	}
+}
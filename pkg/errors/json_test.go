@@ -28,7 +28,12 @@ package errors
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -75,3 +80,106 @@ func TestFrameMarshalJSON(t *testing.T) {
 		}
 	}
 }
+
+func TestMarshalJSONChain(t *testing.T) {
+	_, openErr := os.Open("does-not-exist")
+	err := With(Wrapf(fmt.Errorf("decode: %w", openErr), "load"), "path", "does-not-exist", "attempt", 3)
+
+	data, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+	var layers struct {
+		Type   string
+		Fields map[string]interface{}
+		Stack  []DecodedFrame
+		Causes []struct {
+			Message string
+			Causes  []struct {
+				Message string
+				Type    string
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &layers); err != nil {
+		t.Fatal(err)
+	}
+	if layers.Type != "*errors.tracedError" || layers.Fields["path"] != "does-not-exist" || layers.Fields["attempt"] != 3.0 {
+		t.Errorf("MarshalJSON: got %s, want the type and the fields of the outer layer", data)
+	}
+	if len(layers.Stack) == 0 || layers.Stack[0].Function != "github.com/VirtusLab/go-extended/pkg/errors.TestMarshalJSONChain" ||
+		!strings.HasSuffix(layers.Stack[0].File, "/pkg/errors/json_test.go") || layers.Stack[0].Line == 0 {
+		t.Errorf("MarshalJSON: got %s, want the stack of the outer layer", data)
+	}
+	if len(layers.Causes) != 1 || layers.Causes[0].Message != "load" ||
+		len(layers.Causes[0].Causes) != 1 || layers.Causes[0].Causes[0].Message != "decode" ||
+		layers.Causes[0].Causes[0].Type != "*fmt.wrapError" {
+		t.Errorf("MarshalJSON: got %s, want the messages of every layer", data)
+	}
+
+	if data, err := MarshalJSON(nil); err != nil || string(data) != "null" {
+		t.Errorf("MarshalJSON(nil): got %s, %v, want null", data, err)
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	_, openErr := os.Open("does-not-exist")
+	multi := new(MultiError).Append(New("first"), io.EOF)
+	tests := []error{
+		io.EOF,
+		New("whoops"),
+		Errorf("read: %w", io.EOF),
+		With(Wrapf(fmt.Errorf("decode: %w", openErr), "load"), "path", "does-not-exist"),
+		&causeOnly{Wrap(io.EOF)},
+		Join(New("first"), Wrapf(io.ErrUnexpectedEOF, "second")),
+		multi,
+	}
+	for i, err := range tests {
+		data, marshalErr := MarshalJSON(err)
+		if marshalErr != nil {
+			t.Fatal(marshalErr)
+		}
+		decoded, unmarshalErr := UnmarshalJSON(data)
+		if unmarshalErr != nil {
+			t.Errorf("test %d: UnmarshalJSON(%s): %v", i+1, data, unmarshalErr)
+			continue
+		}
+		if got, want := decoded.Error(), err.Error(); got != want {
+			t.Errorf("test %d: Error(): got %q, want %q", i+1, got, want)
+		}
+		if got, want := Fields(decoded), Fields(err); !reflect.DeepEqual(got, want) {
+			t.Errorf("test %d: Fields(): got %v, want %v", i+1, got, want)
+		}
+		if again, err := MarshalJSON(decoded); err != nil || string(again) != string(data) {
+			t.Errorf("test %d: MarshalJSON of the decoded error:\n got %s\n want %s", i+1, again, data)
+		}
+	}
+
+	if _, err := UnmarshalJSON([]byte("{")); err == nil {
+		t.Errorf("UnmarshalJSON of an invalid JSON: expected an error")
+	}
+}
+
+func TestDecodedErrorFormat(t *testing.T) {
+	data, err := MarshalJSON(With(Wrapf(io.EOF, "read"), "path", "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := UnmarshalJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprintf("%v", decoded), "read: EOF"; got != want {
+		t.Errorf("%%v: got %q, want %q", got, want)
+	}
+	testFormatRegexp(t, 0, decoded, "%+v", "EOF\n"+
+		"read\n"+
+		"github.com/VirtusLab/go-extended/pkg/errors.TestDecodedErrorFormat\n"+
+		"\t.+/pkg/errors/json_test.go:\\d+")
+	if got := fmt.Sprintf("%+v", decoded); !strings.Contains(got, "\npath=config.yaml\n") {
+		t.Errorf("%%+v: got %q, want the fields", got)
+	}
+	if Cause(decoded).Error() != "EOF" {
+		t.Errorf("Cause(): got %v, want the innermost layer", Cause(decoded))
+	}
+}
@@ -0,0 +1,131 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MultiError collects many errors, e.g. from a validation or a batch operation.
+// The zero value is ready to use and it is safe to append to from many goroutines.
+type MultiError struct {
+	mutex sync.Mutex
+	errs  []error
+}
+
+// Append adds the errors, the nil errors are discarded and the errors wrapping many errors,
+// like another MultiError or the result of Join, are flattened.
+// The receiver is returned for chaining.
+func (m *MultiError) Append(errs ...error) *MultiError {
+	flat := flatten(nil, errs)
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.errs = append(m.errs, flat...)
+	return m
+}
+
+func flatten(flat []error, errs []error) []error {
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if many, ok := err.(WithUnwrapMany); ok {
+			flat = flatten(flat, many.Unwrap())
+			continue
+		}
+		flat = append(flat, err)
+	}
+	return flat
+}
+
+// Errors returns a copy of the collected errors, in order of appending
+func (m *MultiError) Errors() []error {
+	if m == nil {
+		return nil
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	errs := make([]error, len(m.errs))
+	copy(errs, m.errs)
+	return errs
+}
+
+// Len returns the number of the collected errors
+func (m *MultiError) Len() int {
+	if m == nil {
+		return 0
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.errs)
+}
+
+// ErrorOrNil returns nil if there are no errors, or the receiver otherwise,
+// use it to return the collected errors as an error
+func (m *MultiError) ErrorOrNil() error {
+	if m.Len() == 0 {
+		return nil
+	}
+	return m
+}
+
+func (m *MultiError) Error() string {
+	errs := m.Errors()
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(len(errs)) + " errors occurred:")
+	for _, err := range errs {
+		b.WriteString("\n\t* " + err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the collected errors, see Is and As
+func (m *MultiError) Unwrap() []error {
+	return m.Errors()
+}
+
+// Is reports whether any of the collected errors matches target, see Is
+func (m *MultiError) Is(target error) bool {
+	for _, err := range m.Errors() {
+		if Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the collected errors that matches target, see As
+func (m *MultiError) As(target interface{}) bool {
+	for _, err := range m.Errors() {
+		if As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Format implements fmt.Formatter used by Sprint(f) or Fprint(f) etc.
+// Under %+v every error is printed with its stack trace.
+func (m *MultiError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			errs := m.Errors()
+			_, _ = io.WriteString(s, strconv.Itoa(len(errs))+" errors occurred:")
+			for _, err := range errs {
+				_, _ = fmt.Fprintf(s, "\n* %+v", err)
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, m.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", m.Error())
+	}
+}
@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMultiErrorAppend(t *testing.T) {
+	var m MultiError
+	if err := m.ErrorOrNil(); err != nil {
+		t.Errorf("ErrorOrNil(): got %v, want nil", err)
+	}
+	m.Append(nil)
+	if err := m.ErrorOrNil(); err != nil {
+		t.Errorf("ErrorOrNil() after appending nil: got %v, want nil", err)
+	}
+
+	inner := new(MultiError).Append(New("inner 1"), New("inner 2"))
+	m.Append(New("first"), nil).Append(inner, Join(io.EOF, nil, io.ErrUnexpectedEOF))
+
+	var messages []string
+	for _, err := range m.Errors() {
+		messages = append(messages, err.Error())
+	}
+	if got, want := strings.Join(messages, ","), "first,inner 1,inner 2,EOF,unexpected EOF"; got != want {
+		t.Errorf("Errors(): got %q, want %q", got, want)
+	}
+	if got, want := m.Len(), 5; got != want {
+		t.Errorf("Len(): got %d, want %d", got, want)
+	}
+
+	want := "5 errors occurred:\n\t* first\n\t* inner 1\n\t* inner 2\n\t* EOF\n\t* unexpected EOF"
+	if got := m.ErrorOrNil().Error(); got != want {
+		t.Errorf("Error(): got %q, want %q", got, want)
+	}
+	if got := new(MultiError).Append(io.EOF).Error(); got != "EOF" {
+		t.Errorf("Error() of a single error: got %q, want %q", got, "EOF")
+	}
+
+	var nilMulti *MultiError
+	if nilMulti.ErrorOrNil() != nil || nilMulti.Len() != 0 || nilMulti.Errors() != nil {
+		t.Errorf("nil MultiError: expected no errors")
+	}
+}
+
+func TestMultiErrorConcurrent(t *testing.T) {
+	var m MultiError
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m.Append(fmt.Errorf("error %d", i))
+			_ = m.Error()
+		}(i)
+	}
+	wg.Wait()
+	if got := m.Len(); got != 100 {
+		t.Errorf("Len(): got %d, want 100", got)
+	}
+}
+
+func TestMultiErrorIsAs(t *testing.T) {
+	_, openErr := os.Open("does-not-exist")
+	err := new(MultiError).Append(New("first"), Wrapf(openErr, "open")).ErrorOrNil()
+	wrapped := fmt.Errorf("batch: %w", err)
+
+	if !Is(wrapped, openErr) {
+		t.Errorf("Is(%v): got false, want true", wrapped)
+	}
+	if Is(wrapped, io.EOF) {
+		t.Errorf("Is(%v, io.EOF): got true, want false", wrapped)
+	}
+	var pathError *os.PathError
+	if !As(wrapped, &pathError) || pathError.Path != "does-not-exist" {
+		t.Errorf("As(%v): got %v, want the path error", wrapped, pathError)
+	}
+	var multi *MultiError
+	if !As(wrapped, &multi) || multi.Len() != 2 {
+		t.Errorf("As(%v): got %v, want the multi error", wrapped, multi)
+	}
+}
+
+func TestMultiErrorFormat(t *testing.T) {
+	err := new(MultiError).Append(New("first"), io.EOF)
+	if got, want := fmt.Sprintf("%v", err), "2 errors occurred:\n\t* first\n\t* EOF"; got != want {
+		t.Errorf("%%v: got %q, want %q", got, want)
+	}
+	if got, want := fmt.Sprintf("%q", err), `"2 errors occurred:\n\t* first\n\t* EOF"`; got != want {
+		t.Errorf("%%q: got %q, want %q", got, want)
+	}
+	testFormatRegexp(t, 0, err, "%+v", "2 errors occurred:\n"+
+		"\\* first\n"+
+		"github.com/VirtusLab/go-extended/pkg/errors.TestMultiErrorFormat\n"+
+		"\t.+/pkg/errors/multi_test.go:\\d+")
+	if got := fmt.Sprintf("%+v", err); !strings.HasSuffix(got, "\n* EOF") {
+		t.Errorf("%%+v: got %q, want the second error last", got)
+	}
+}
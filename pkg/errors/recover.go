@@ -0,0 +1,107 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// PanicError is the cause of the errors converted from a recovered panic, see Recover.
+// Use As to get the original panic value.
+type PanicError struct {
+	// Value is the value passed to panic
+	Value interface{}
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", p.Value)
+}
+
+// Unwrap returns the panic value if it is an error, see Is and As
+func (p *PanicError) Unwrap() error {
+	if err, ok := p.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// Recover converts a panic into an error stored in err, it must be called with defer, e.g.
+//
+//	func run() (err error) {
+//	    defer errors.Recover(&err)
+//	    ...
+//	}
+//
+// The error has the Internal code, the stack trace of the place that panicked,
+// and a PanicError cause holding the original value. An error already stored in err is replaced.
+// Recover does nothing if there is no panic.
+func Recover(err *error) {
+	if value := recover(); value != nil {
+		*err = fromPanic(value)
+	}
+}
+
+// Catch runs fn and returns its error, or the error converted from a panic in fn, see Recover
+func Catch(fn func() error) (err error) {
+	defer Recover(&err)
+	return fn()
+}
+
+// SafeGo runs fn in a new goroutine, a panic in fn is converted to an error, see Recover.
+// The returned channel receives the error of fn, or nil, and is closed when fn returns.
+func SafeGo(fn func() error) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		defer close(result)
+		result <- Catch(fn)
+	}()
+	return result
+}
+
+func fromPanic(value interface{}) error {
+	return &tracedError{
+		cause: &PanicError{Value: value},
+		code:  Internal,
+		stack: panicCallers(),
+	}
+}
+
+// panicCallers gets a stack dump starting at the place that panicked, it follows the StackConfig
+// the same as the stacks of the created errors, with the frames skipped from the place that panicked.
+// It must be called from a function deferred by the panicking goroutine
+func panicCallers() *Stack {
+	config := stackConfig.Load().(StackConfig)
+	if config.Disabled {
+		return &Stack{}
+	}
+	depth := config.depth(nil)
+
+	// the deferred functions and the panic internals are above the place that panicked
+	const extra = 16
+	pcs := make([]uintptr, depth+config.Skip+extra)
+	n := runtime.Callers(2, pcs)
+	pcs = pcs[:n]
+	for i, pc := range pcs {
+		if Frame(pc).name() != "runtime.gopanic" {
+			continue
+		}
+		start := i + 1
+		for start < len(pcs) && strings.HasPrefix(Frame(pcs[start]).name(), "runtime.") {
+			start++
+		}
+		pcs = pcs[start:]
+		break
+	}
+	if config.Skip >= len(pcs) {
+		return &Stack{}
+	}
+	pcs = pcs[config.Skip:]
+	if !config.Lazy && inPackages(pcs[0], config.DisabledPackages) {
+		return &Stack{}
+	}
+	if len(pcs) > depth {
+		pcs = pcs[:depth]
+	}
+	st := Stack(pcs)
+	return &st
+}
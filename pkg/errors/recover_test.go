@@ -0,0 +1,127 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func panicking(value interface{}) {
+	panic(value)
+}
+
+func nilDereference() int {
+	var p *int
+	return *p
+}
+
+func TestRecover(t *testing.T) {
+	run := func(value interface{}) (err error) {
+		defer Recover(&err)
+		panicking(value)
+		return nil
+	}
+
+	err := run("boom")
+	if err == nil {
+		t.Fatal("Recover: got nil, want an error")
+	}
+	if got, want := err.Error(), "panic: boom"; got != want {
+		t.Errorf("Error(): got %q, want %q", got, want)
+	}
+	var panicErr *PanicError
+	if !As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Errorf("As(PanicError): got %v, want the panic value", panicErr)
+	}
+	if got := CodeOf(err); got != Internal {
+		t.Errorf("CodeOf(): got %v, want Internal", got)
+	}
+	if got, want := firstFunction(err), "github.com/VirtusLab/go-extended/pkg/errors.panicking"; got != want {
+		t.Errorf("stack: got %s first, want the panic site %s", got, want)
+	}
+
+	err = run(io.EOF)
+	if !Is(err, io.EOF) {
+		t.Errorf("Is(io.EOF): got false, want true for %v", err)
+	}
+
+	noPanic := func() (err error) {
+		defer Recover(&err)
+		return io.ErrUnexpectedEOF
+	}
+	if err := noPanic(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Recover without a panic: got %v, want the returned error", err)
+	}
+}
+
+func TestRecoverStackConfig(t *testing.T) {
+	catchPanic := func() error {
+		return Catch(func() error {
+			panicking("boom")
+			return nil
+		})
+	}
+
+	withStackConfig(t, StackConfig{Skip: 1, Depth: 2})
+	st := catchPanic().(WithStackTrace).StackTrace()
+	if len(st) != 2 || !strings.HasPrefix(st[0].name(), "github.com/VirtusLab/go-extended/pkg/errors.TestRecoverStackConfig.") {
+		t.Errorf("Skip 1, Depth 2: got %v, want 2 frames from the caller of the panic site", st)
+	}
+
+	SetStackConfig(StackConfig{SampleRate: 2})
+	if a, b := len(catchPanic().(WithStackTrace).StackTrace()), len(catchPanic().(WithStackTrace).StackTrace()); (a == 1) == (b == 1) {
+		t.Errorf("SampleRate 2: got %d and %d frames, want one of them sampled out", a, b)
+	}
+
+	for _, config := range []StackConfig{
+		{DisabledPackages: []string{"github.com/VirtusLab/go-extended/pkg/errors"}},
+		{Lazy: true, DisabledPackages: []string{"github.com/VirtusLab/go-extended/pkg/errors"}},
+	} {
+		SetStackConfig(config)
+		if got := len(catchPanic().(WithStackTrace).StackTrace()); got != 0 {
+			t.Errorf("DisabledPackages with Lazy %v: got %d frames, want 0", config.Lazy, got)
+		}
+	}
+}
+
+func TestCatch(t *testing.T) {
+	if err := Catch(func() error { return nil }); err != nil {
+		t.Errorf("Catch: got %v, want nil", err)
+	}
+	if err := Catch(func() error { return io.EOF }); err != io.EOF {
+		t.Errorf("Catch: got %v, want io.EOF", err)
+	}
+
+	err := Catch(func() error {
+		nilDereference()
+		return nil
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "panic: runtime error: invalid memory address or nil pointer dereference") {
+		t.Fatalf("Catch: got %v, want a nil dereference panic", err)
+	}
+	if got, want := firstFunction(err), "github.com/VirtusLab/go-extended/pkg/errors.nilDereference"; got != want {
+		t.Errorf("stack: got %s first, want the panic site %s", got, want)
+	}
+	testFormatRegexp(t, 0, err, "%+v", "panic: runtime error: invalid memory address or nil pointer dereference.*\n"+
+		"github.com/VirtusLab/go-extended/pkg/errors.nilDereference\n"+
+		"\t.+/pkg/errors/recover_test.go:\\d+")
+}
+
+func TestSafeGo(t *testing.T) {
+	err := <-SafeGo(func() error {
+		panicking(fmt.Sprintf("in goroutine %d", 1))
+		return nil
+	})
+	if err == nil || err.Error() != "panic: in goroutine 1" {
+		t.Errorf("SafeGo: got %v, want the panic", err)
+	}
+
+	result := SafeGo(func() error { return io.EOF })
+	if err := <-result; err != io.EOF {
+		t.Errorf("SafeGo: got %v, want io.EOF", err)
+	}
+	if _, open := <-result; open {
+		t.Errorf("SafeGo: expected the channel to be closed")
+	}
+}
@@ -166,21 +166,26 @@ func (st StackTrace) formatSlice(s fmt.State, verb rune) {
 type Stack []uintptr
 
 // Format implements fmt.Formatter used by Sprint(f) or Fprint(f) etc.
+// The frames are printed with %+v, using the current stack format, see SetStackFormat.
 func (s *Stack) Format(st fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		switch {
 		case st.Flag('+'):
-			for _, pc := range *s {
-				f := Frame(pc)
-				_, _ = fmt.Fprintf(st, "\n%+v", f)
-			}
+			format := stackFormat.Load().(StackFormat)
+			_, _ = io.WriteString(st, format.FormatStackTrace(s.StackTrace()))
 		}
 	}
 }
 
-// StackTrace creates a StackTrace for this Stack
+// StackTrace creates a StackTrace for this Stack, it is empty if the stack was captured lazily
+// in one of the disabled packages, see StackConfig
 func (s *Stack) StackTrace() StackTrace {
+	if len(*s) > 0 {
+		if config := stackConfig.Load().(StackConfig); config.Lazy && inPackages((*s)[0], config.DisabledPackages) {
+			return StackTrace{}
+		}
+	}
 	f := make([]Frame, len(*s))
 	for i := 0; i < len(f); i++ {
 		f[i] = Frame((*s)[i])
@@ -188,13 +193,10 @@ func (s *Stack) StackTrace() StackTrace {
 	return f
 }
 
-// Callers gets a stack dump using runtime.Callers
+// Callers gets a stack dump using runtime.Callers, starting at the caller of the function calling Callers.
+// The capture can be configured with SetStackConfig.
 func Callers() *Stack {
-	const depth = 32
-	var pcs [depth]uintptr
-	n := runtime.Callers(3, pcs[:])
-	var st Stack = pcs[0:n]
-	return &st
+	return capture(2, nil)
 }
 
 // funcName removes the path prefix component of a function's name reported by func.Name().
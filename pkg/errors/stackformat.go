@@ -0,0 +1,175 @@
+package errors
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// the ANSI escape codes used by StackFormat.Color
+const (
+	colorFunction = "\x1b[1m"
+	colorLocation = "\x1b[36m"
+	colorFaint    = "\x1b[2m"
+	colorReset    = "\x1b[0m"
+)
+
+// hiddenPackages are the packages dropped by StackFormat.HideRuntime, with their sub packages
+var hiddenPackages = []string{"runtime", "testing"}
+
+// StackFormat configures how the stack traces of the errors are printed with %+v and %#v.
+// The zero value prints every frame as the function name and the full path of the file.
+type StackFormat struct {
+	// HideRuntime drops the frames of the runtime and testing packages, e.g. runtime.goexit and testing.tRunner
+	HideRuntime bool
+	// Collapse prints the consecutive frames of the functions with any of the given prefixes as a single line,
+	// e.g. "github.com/stretchr/testify" or a vendored library
+	Collapse []string
+	// Root is printed as a relative path, e.g. the module root
+	Root string
+	// Color colorizes the output with ANSI escape codes, e.g. for a terminal
+	Color bool
+}
+
+var stackFormat atomic.Value
+
+func init() {
+	stackFormat.Store(StackFormat{})
+}
+
+// SetStackFormat sets how the stack traces are printed from now on, it is safe for concurrent use
+func SetStackFormat(format StackFormat) {
+	format.Collapse = append([]string(nil), format.Collapse...)
+	stackFormat.Store(format)
+}
+
+// CurrentStackFormat returns the current stack format
+func CurrentStackFormat() StackFormat {
+	format := stackFormat.Load().(StackFormat)
+	format.Collapse = append([]string(nil), format.Collapse...)
+	return format
+}
+
+// FormatStackTrace returns the frames, each preceded by a newline, the same as %+v of a StackTrace
+// with the format options applied
+func (f StackFormat) FormatStackTrace(st StackTrace) string {
+	var b strings.Builder
+	for i := 0; i < len(st); i++ {
+		name := st[i].name()
+		if f.HideRuntime && hasPackagePrefix(packageName(name), hiddenPackages) {
+			continue
+		}
+		if prefix := matchingPrefix(name, f.Collapse); prefix != "" {
+			n := 1
+			for i+n < len(st) && matchingPrefix(st[i+n].name(), f.Collapse) == prefix {
+				n++
+			}
+			i += n - 1
+			b.WriteString("\n" + f.colorize(colorFaint, prefix+" ("+strconv.Itoa(n)+" frames)"))
+			continue
+		}
+		location := f.relative(st[i].file()) + ":" + strconv.Itoa(st[i].line())
+		b.WriteString("\n" + f.colorize(colorFunction, name) + "\n\t" + f.colorize(colorLocation, location))
+	}
+	return b.String()
+}
+
+// Sprint returns the message of err followed by the merged stack trace of its whole chain, see MergedStackTrace.
+// The same is printed by %#v of the errors created by this package, using the current stack format.
+func (f StackFormat) Sprint(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error() + f.FormatStackTrace(MergedStackTrace(err))
+}
+
+func (f StackFormat) relative(file string) string {
+	if f.Root == "" {
+		return file
+	}
+	root := strings.TrimSuffix(f.Root, "/") + "/"
+	return strings.TrimPrefix(file, root)
+}
+
+func (f StackFormat) colorize(color, text string) string {
+	if !f.Color {
+		return text
+	}
+	return color + text + colorReset
+}
+
+func hasPackagePrefix(pkg string, packages []string) bool {
+	for _, p := range packages {
+		if pkg == p || strings.HasPrefix(pkg, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func matchingPrefix(name string, prefixes []string) string {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// MergedStackTrace returns a single stack trace for the whole chain of err, followed using both Unwrap and Cause.
+// It starts with the stack trace of the innermost error, the frames of the wrapping errors
+// not already present are inserted above the frames they share with it.
+func MergedStackTrace(err error) StackTrace {
+	var stacks []StackTrace
+	collectStackTraces(err, &stacks)
+	if len(stacks) == 0 {
+		return nil
+	}
+
+	merged := append(StackTrace(nil), stacks[len(stacks)-1]...)
+	for i := len(stacks) - 2; i >= 0; i-- {
+		outer := stacks[i]
+		common := 0
+		for common < len(outer) && common < len(merged) &&
+			outer[len(outer)-1-common] == merged[len(merged)-1-common] {
+			common++
+		}
+		var unique StackTrace
+		for _, frame := range outer[:len(outer)-common] {
+			if !containsFrame(merged, frame) {
+				unique = append(unique, frame)
+			}
+		}
+		at := len(merged) - common
+		merged = append(merged[:at], append(unique, merged[at:]...)...)
+	}
+	return merged
+}
+
+// collectStackTraces appends the stack traces along the chain of err, the outermost first
+func collectStackTraces(err error, stacks *[]StackTrace) {
+	for err != nil {
+		if withStack, ok := err.(WithStackTrace); ok {
+			if st := withStack.StackTrace(); len(st) > 0 {
+				*stacks = append(*stacks, st)
+			}
+		}
+		wrapped := unwrap(err)
+		if len(wrapped) != 1 {
+			for _, e := range wrapped {
+				collectStackTraces(e, stacks)
+			}
+			return
+		}
+		err = wrapped[0]
+	}
+}
+
+func containsFrame(st StackTrace, frame Frame) bool {
+	for _, f := range st {
+		if f == frame {
+			return true
+		}
+	}
+	return false
+}
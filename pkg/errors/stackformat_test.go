@@ -0,0 +1,121 @@
+package errors
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func withStackFormat(t testing.TB, format StackFormat) {
+	previous := CurrentStackFormat()
+	SetStackFormat(format)
+	t.Cleanup(func() { SetStackFormat(previous) })
+}
+
+func newInHelper() error {
+	return New("inner")
+}
+
+func TestStackFormatDefault(t *testing.T) {
+	withStackFormat(t, StackFormat{})
+	st := New("whoops").(WithStackTrace).StackTrace()
+	if got, want := (StackFormat{}).FormatStackTrace(st), fmt.Sprintf("%+v", st); got != want {
+		t.Errorf("FormatStackTrace: got %q, want %q", got, want)
+	}
+}
+
+func TestStackFormatHideRuntime(t *testing.T) {
+	withStackFormat(t, StackFormat{HideRuntime: true})
+	got := fmt.Sprintf("%+v", New("whoops"))
+	if strings.Contains(got, "testing.tRunner") || strings.Contains(got, "runtime.goexit") {
+		t.Errorf("HideRuntime: got %q, want no runtime and testing frames", got)
+	}
+	if !strings.Contains(got, "pkg/errors.TestStackFormatHideRuntime\n") {
+		t.Errorf("HideRuntime: got %q, want the test frame", got)
+	}
+}
+
+func TestStackFormatCollapse(t *testing.T) {
+	withStackFormat(t, StackFormat{Collapse: []string{"github.com/VirtusLab/go-extended/pkg/errors.deepNew"}})
+	got := fmt.Sprintf("%+v", deepNew(5))
+	want := "deep\ngithub.com/VirtusLab/go-extended/pkg/errors.deepNew (6 frames)\n" +
+		"github.com/VirtusLab/go-extended/pkg/errors.TestStackFormatCollapse\n"
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("Collapse: got %q, want the prefix %q", got, want)
+	}
+}
+
+func TestStackFormatRoot(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	withStackFormat(t, StackFormat{Root: filepath.Dir(file)})
+	got := fmt.Sprintf("%+v", New("whoops"))
+	if !regexp.MustCompile("^whoops\ngithub.com/VirtusLab/go-extended/pkg/errors.TestStackFormatRoot\n\tstackformat_test.go:\\d+\n").MatchString(got) {
+		t.Errorf("Root: got %q, want a relative path", got)
+	}
+}
+
+func TestStackFormatColor(t *testing.T) {
+	withStackFormat(t, StackFormat{Color: true, Collapse: []string{"testing."}})
+	got := fmt.Sprintf("%+v", New("whoops"))
+	for _, want := range []string{
+		"\x1b[1mgithub.com/VirtusLab/go-extended/pkg/errors.TestStackFormatColor\x1b[0m\n\t\x1b[36m",
+		"\x1b[2mtesting. (1 frames)\x1b[0m",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Color: got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestMergedStackTrace(t *testing.T) {
+	inner := newInHelper()
+	err := Wrapf(fmt.Errorf("middle: %w", inner), "outer")
+
+	innerStack := inner.(WithStackTrace).StackTrace()
+	merged := MergedStackTrace(err)
+	if len(merged) != len(innerStack)+1 {
+		t.Fatalf("MergedStackTrace: got %d frames, want %d", len(merged), len(innerStack)+1)
+	}
+	names := []string{merged[0].name(), merged[1].name(), merged[2].name()}
+	want := []string{
+		"github.com/VirtusLab/go-extended/pkg/errors.newInHelper",
+		"github.com/VirtusLab/go-extended/pkg/errors.TestMergedStackTrace",
+		"github.com/VirtusLab/go-extended/pkg/errors.TestMergedStackTrace",
+	}
+	if fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("MergedStackTrace: got %v, want %v", names, want)
+	}
+	if merged[1].line() >= merged[2].line() {
+		t.Errorf("MergedStackTrace: got lines %d and %d, want the call of the helper before the wrap", merged[1].line(), merged[2].line())
+	}
+
+	if got := MergedStackTrace(fmt.Errorf("no stack")); got != nil {
+		t.Errorf("MergedStackTrace without stacks: got %v, want nil", got)
+	}
+}
+
+func TestFormatMergedStack(t *testing.T) {
+	withStackFormat(t, StackFormat{HideRuntime: true})
+	err := Wrapf(newInHelper(), "outer")
+	want := "outer: inner" + CurrentStackFormat().FormatStackTrace(MergedStackTrace(err))
+	if got := fmt.Sprintf("%#v", err); got != want {
+		t.Errorf("%%#v: got %q, want %q", got, want)
+	}
+	if got := (StackFormat{HideRuntime: true}).Sprint(err); got != want {
+		t.Errorf("Sprint: got %q, want %q", got, want)
+	}
+	if got := strings.Count(want, "pkg/errors.newInHelper\n"); got != 1 {
+		t.Errorf("%%#v: got the inner frame %d times, want once", got)
+	}
+
+	custom := ErrCustom("custom")
+	if got, want := fmt.Sprintf("%#v", custom), "custom"+CurrentStackFormat().FormatStackTrace(custom.StackTrace()); got != want {
+		t.Errorf("%%#v of a custom error: got %q, want %q", got, want)
+	}
+	if got := (StackFormat{}).Sprint(nil); got != "" {
+		t.Errorf("Sprint(nil): got %q, want empty", got)
+	}
+}
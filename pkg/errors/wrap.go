@@ -0,0 +1,188 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// WithUnwrap represents an error that wraps another error, the same as used by the standard errors package
+type WithUnwrap interface {
+	// Unwrap returns the wrapped error
+	Unwrap() error
+}
+
+// WithUnwrapMany represents an error that wraps many errors, e.g. returned by Join
+type WithUnwrapMany interface {
+	// Unwrap returns the wrapped errors
+	Unwrap() []error
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Unwrap returns the error wrapped by err, using Unwrap or Cause, whichever err implements.
+// Unwrap returns nil if err wraps nothing, or wraps many errors.
+func Unwrap(err error) error {
+	wrapped := unwrap(err)
+	if len(wrapped) != 1 {
+		return nil
+	}
+	return wrapped[0]
+}
+
+// unwrap returns the errors wrapped by err, Unwrap takes precedence over Cause
+func unwrap(err error) []error {
+	switch e := err.(type) {
+	case WithUnwrapMany:
+		return e.Unwrap()
+	case WithUnwrap:
+		if wrapped := e.Unwrap(); wrapped != nil {
+			return []error{wrapped}
+		}
+	case WithCause:
+		if cause := e.Cause(); cause != nil {
+			return []error{cause}
+		}
+	}
+	return nil
+}
+
+// Is reports whether any error in the chain of err matches target, the same as the standard errors.Is,
+// but the chain is followed using both Unwrap and Cause, including the errors wrapped by Join.
+// An error matches the target if it is equal to it, or if it has an Is(error) bool method that returns true.
+func Is(err, target error) bool {
+	if err == nil || target == nil {
+		return err == target
+	}
+	return is(err, target, reflect.TypeOf(target).Comparable())
+}
+
+func is(err, target error, comparable bool) bool {
+	for {
+		if comparable && err == target {
+			return true
+		}
+		if x, ok := err.(interface{ Is(error) bool }); ok && x.Is(target) {
+			return true
+		}
+		wrapped := unwrap(err)
+		switch len(wrapped) {
+		case 0:
+			return false
+		case 1:
+			err = wrapped[0]
+		default:
+			for _, e := range wrapped {
+				if e != nil && is(e, target, comparable) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+}
+
+// As finds the first error in the chain of err that matches target, and if so, sets target to that error
+// and returns true, the same as the standard errors.As, but the chain is followed using both Unwrap and Cause,
+// including the errors wrapped by Join.
+// As panics if target is not a non-nil pointer to either a type that implements error, or to any interface type.
+func As(err error, target interface{}) bool {
+	if target == nil {
+		panic("errors: target cannot be nil")
+	}
+	val := reflect.ValueOf(target)
+	typ := val.Type()
+	if typ.Kind() != reflect.Ptr || val.IsNil() {
+		panic("errors: target must be a non-nil pointer")
+	}
+	targetType := typ.Elem()
+	if targetType.Kind() != reflect.Interface && !targetType.Implements(errorType) {
+		panic("errors: *target must be interface or implement error")
+	}
+	if err == nil {
+		return false
+	}
+	return as(err, target, val, targetType)
+}
+
+func as(err error, target interface{}, val reflect.Value, targetType reflect.Type) bool {
+	for {
+		if reflect.TypeOf(err).AssignableTo(targetType) {
+			val.Elem().Set(reflect.ValueOf(err))
+			return true
+		}
+		if x, ok := err.(interface{ As(interface{}) bool }); ok && x.As(target) {
+			return true
+		}
+		wrapped := unwrap(err)
+		switch len(wrapped) {
+		case 0:
+			return false
+		case 1:
+			err = wrapped[0]
+		default:
+			for _, e := range wrapped {
+				if e != nil && as(e, target, val, targetType) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+}
+
+type joinedError struct {
+	errs []error
+}
+
+// Join returns an error that wraps the given errors, the nil errors are discarded.
+// Join returns nil if every error is nil. The message of the joined error
+// holds the messages of the errors separated with newlines.
+// The joined errors can be matched with Is and As.
+func Join(errs ...error) error {
+	var nonNil []error
+	for _, err := range errs {
+		if err != nil {
+			nonNil = append(nonNil, err)
+		}
+	}
+	if len(nonNil) == 0 {
+		return nil
+	}
+	return &joinedError{errs: nonNil}
+}
+
+func (j *joinedError) Error() string {
+	messages := make([]string, len(j.errs))
+	for i, err := range j.errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the joined errors
+func (j *joinedError) Unwrap() []error {
+	return j.errs
+}
+
+// Format implements fmt.Formatter used by Sprint(f) or Fprint(f) etc.
+func (j *joinedError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			for i, err := range j.errs {
+				if i > 0 {
+					_, _ = io.WriteString(s, "\n")
+				}
+				_, _ = fmt.Fprintf(s, "%+v", err)
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, j.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", j.Error())
+	}
+}
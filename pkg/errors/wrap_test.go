@@ -0,0 +1,135 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// causeOnly is an error wrapping another one with Cause only, like the older github.com/pkg/errors
+type causeOnly struct {
+	cause error
+}
+
+func (c *causeOnly) Error() string { return "cause only: " + c.cause.Error() }
+func (c *causeOnly) Cause() error  { return c.cause }
+
+type isEOF struct{}
+
+func (isEOF) Error() string        { return "looks like EOF" }
+func (isEOF) Is(target error) bool { return target == io.EOF }
+
+func TestUnwrap(t *testing.T) {
+	tests := []struct {
+		err  error
+		want error
+	}{
+		{nil, nil},
+		{io.EOF, nil},
+		{Wrap(io.EOF), io.EOF},
+		{Wrapf(io.EOF, "read"), io.EOF},
+		{&causeOnly{io.EOF}, io.EOF},
+		{fmt.Errorf("read: %w", io.EOF), io.EOF},
+		{New("no cause"), nil},
+		{Join(io.EOF, io.ErrUnexpectedEOF), nil},
+	}
+	for i, tt := range tests {
+		if got := Unwrap(tt.err); got != tt.want {
+			t.Errorf("test %d: Unwrap(%v): got %v, want %v", i+1, tt.err, got, tt.want)
+		}
+	}
+
+	// the standard library sees through the wrapped errors too
+	if !errors.Is(Wrapf(io.EOF, "read"), io.EOF) {
+		t.Errorf("errors.Is(Wrapf(io.EOF)): got false, want true")
+	}
+}
+
+func TestIs(t *testing.T) {
+	sentinel := New("sentinel")
+	tests := []struct {
+		err    error
+		target error
+		want   bool
+	}{
+		{nil, nil, true},
+		{nil, io.EOF, false},
+		{io.EOF, nil, false},
+		{io.EOF, io.EOF, true},
+		{Wrap(io.EOF), io.EOF, true},
+		{Wrapf(fmt.Errorf("decode: %w", Wrapf(io.EOF, "read")), "load"), io.EOF, true},
+		{fmt.Errorf("load: %w", Wrapf(&causeOnly{io.EOF}, "read")), io.EOF, true},
+		{&causeOnly{fmt.Errorf("read: %w", sentinel)}, sentinel, true},
+		{Errorf("read: %w", sentinel), sentinel, true},
+		{Errorf("read: %v", sentinel), sentinel, false},
+		{Wrap(isEOF{}), io.EOF, true},
+		{Join(io.ErrUnexpectedEOF, Wrap(io.EOF)), io.EOF, true},
+		{fmt.Errorf("many: %w", Join(nil, io.ErrUnexpectedEOF)), io.EOF, false},
+		{Wrap(io.ErrUnexpectedEOF), io.EOF, false},
+	}
+	for i, tt := range tests {
+		if got := Is(tt.err, tt.target); got != tt.want {
+			t.Errorf("test %d: Is(%v, %v): got %t, want %t", i+1, tt.err, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestAs(t *testing.T) {
+	var pathError *os.PathError
+	_, openErr := os.Open("does-not-exist")
+	err := Wrapf(fmt.Errorf("config: %w", &causeOnly{Wrap(openErr)}), "load")
+	if !As(err, &pathError) || pathError.Path != "does-not-exist" {
+		t.Errorf("As(%v): got %v, want the path error", err, pathError)
+	}
+
+	var custom *CustomError
+	err = Join(io.EOF, fmt.Errorf("second: %w", ErrCustom("custom")))
+	if !As(err, &custom) || custom.s != "custom" {
+		t.Errorf("As(%v): got %v, want the custom error", err, custom)
+	}
+
+	var withStack WithStackTrace
+	if !As(fmt.Errorf("wrapped: %w", New("traced")), &withStack) {
+		t.Errorf("As(WithStackTrace): got false, want true")
+	}
+
+	if As(Wrap(io.EOF), &pathError) {
+		t.Errorf("As(io.EOF): got true, want false")
+	}
+	if As(nil, &pathError) {
+		t.Errorf("As(nil): got true, want false")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("As with a non-pointer target: expected panic")
+		}
+	}()
+	As(io.EOF, pathError)
+}
+
+func TestJoin(t *testing.T) {
+	if err := Join(); err != nil {
+		t.Errorf("Join(): got %v, want nil", err)
+	}
+	if err := Join(nil, nil); err != nil {
+		t.Errorf("Join(nil, nil): got %v, want nil", err)
+	}
+
+	err := Join(New("first"), nil, fmt.Errorf("second"))
+	if got, want := err.Error(), "first\nsecond"; got != want {
+		t.Errorf("Join.Error(): got %q, want %q", got, want)
+	}
+	if got, want := fmt.Sprintf("%v", err), "first\nsecond"; got != want {
+		t.Errorf("Join %%v: got %q, want %q", got, want)
+	}
+	testFormatRegexp(t, 0, err, "%+v", "first\n"+
+		"github.com/VirtusLab/go-extended/pkg/errors.TestJoin\n"+
+		"\t.+/pkg/errors/wrap_test.go:\\d+")
+	if got := fmt.Sprintf("%+v", err); !strings.HasSuffix(got, "\nsecond") {
+		t.Errorf("Join %%+v: got %q, want the second error last", got)
+	}
+}
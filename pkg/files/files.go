@@ -15,9 +15,13 @@ import (
 // ErrExpectedStdin indicates that an stdin pipe was expected but not present
 type ErrExpectedStdin struct {
 	stack *errors.Stack
+	cause error
 }
 
 func (e *ErrExpectedStdin) Error() string {
+	if e.cause != nil {
+		return "expected a pipe stdin: " + e.cause.Error()
+	}
 	return "expected a pipe stdin"
 }
 
@@ -31,6 +35,21 @@ func (e *ErrExpectedStdin) StackTrace() errors.StackTrace {
 	return e.stack.StackTrace()
 }
 
+// Cause returns the error that prevented checking the stdin, if any
+func (e *ErrExpectedStdin) Cause() error {
+	return e.cause
+}
+
+// Unwrap returns the error that prevented checking the stdin, if any
+func (e *ErrExpectedStdin) Unwrap() error {
+	return e.cause
+}
+
+// Code returns errors.InvalidArgument
+func (e *ErrExpectedStdin) Code() errors.Code {
+	return errors.InvalidArgument
+}
+
 // NewErrExpectedStdin creates a new ErrExpectedStdin
 func NewErrExpectedStdin() *ErrExpectedStdin {
 	return &ErrExpectedStdin{
@@ -49,7 +68,12 @@ type FileEntry struct {
 func ReadInput(path string) ([]byte, error) {
 	var inputFile *os.File
 	if path == "" {
-		stdinFileInfo, _ := os.Stdin.Stat()
+		stdinFileInfo, err := os.Stdin.Stat()
+		if err != nil {
+			e := NewErrExpectedStdin()
+			e.cause = err
+			return nil, e
+		}
 		if (stdinFileInfo.Mode() & os.ModeNamedPipe) != 0 {
 			inputFile = os.Stdin
 		} else {
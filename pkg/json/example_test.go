@@ -46,3 +46,21 @@ func ExampleToInterface_simple() {
 	// map[string]interface {}
 	// map
 }
+
+func ExampleToInterfaces() {
+	js := `{"name": "first"}
+{"name": "second"} ["third"]`
+
+	documents, err := json.ToInterfaces(strings.NewReader(js))
+	if err != nil {
+		_, _ = fmt.Fprint(os.Stderr, err)
+	}
+	for _, document := range documents {
+		fmt.Println(document)
+	}
+
+	// Output:
+	// map[name:first]
+	// map[name:second]
+	// [third]
+}
@@ -22,3 +22,21 @@ func ToInterface(reader io.Reader) (interface{}, error) {
 	}
 	return result, nil
 }
+
+// ToInterfaces unmarshalls all JSON values from the reader to "generic" interfaces,
+// e.g. JSON lines or concatenated JSON documents
+func ToInterfaces(reader io.Reader) ([]interface{}, error) {
+	var results []interface{}
+	decoder := json.NewDecoder(reader)
+	for {
+		var result interface{}
+		err := decoder.Decode(&result)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,57 @@
+package jsonpath
+
+// Analysis describes what an expression reads and how many results it may return
+type Analysis struct {
+	// Definite is true when every action of the expression returns at most a single value
+	Definite bool
+	// Keys holds the field names read by the expression, in order of appearance and without duplicates
+	Keys []string
+}
+
+// Analyze parses the expression and reports its properties without evaluating it,
+// e.g. to validate user provided expressions before running them
+func Analyze(expression string, options ...func(*Parser)) (*Analysis, error) {
+	p, err := Parse("analyze", expression, options...)
+	if err != nil {
+		return nil, err
+	}
+	return AnalyzeNode(p.Root), nil
+}
+
+// AnalyzeNode reports the properties of the tree rooted at node, see Analyze
+func AnalyzeNode(node Node) *Analysis {
+	analysis := &Analysis{
+		Definite: isDefinite(node),
+	}
+	seen := map[string]bool{}
+	Inspect(node, func(n Node) bool {
+		if field, ok := n.(*FieldNode); ok && !seen[field.Value] {
+			seen[field.Value] = true
+			analysis.Keys = append(analysis.Keys, field.Value)
+		}
+		return true
+	})
+	return analysis
+}
+
+// isDefinite reports whether the steps of the tree rooted at node select at most a single value,
+// the filter operands and the function arguments are evaluated per value and do not matter
+func isDefinite(node Node) bool {
+	switch node := node.(type) {
+	case *ListNode:
+		for _, n := range node.Nodes {
+			if !isDefinite(n) {
+				return false
+			}
+		}
+		return true
+	case *ArrayNode:
+		return node.Params[1].Derived
+	case *IdentifierNode:
+		// a range iterates over many values
+		return node.Name != "range"
+	case *FilterNode, *WildcardNode, *RecursiveNode, *UnionNode:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package jsonpath
+
+import (
+	"reflect"
+	"testing"
+)
+
+type analyzeTest struct {
+	name     string
+	text     string
+	definite bool
+	keys     []string
+}
+
+func TestAnalyze(t *testing.T) {
+	analyzeTests := []analyzeTest{
+		{"plain", `hello jsonpath`, true, nil},
+		{"field", `{.metadata.name}`, true, []string{"metadata", "name"}},
+		{"index", `{.items[-1].name}`, true, []string{"items", "name"}},
+		{"function", `{length(@.items)}`, true, []string{"items"}},
+		{"stage", `{.metadata | sortBy(.name)}`, true, []string{"metadata", "name"}},
+		{"many actions", `{.metadata.name}: {.status.phase}`, true, []string{"metadata", "name", "status", "phase"}},
+		{"slice", `{.items[0:2].name}`, false, []string{"items", "name"}},
+		{"wildcard", `{.items[*].name}`, false, []string{"items", "name"}},
+		{"filter", `{.items[?(@.kind == "Pod")].metadata.name}`, false, []string{"items", "kind", "metadata", "name"}},
+		{"recursive", `{..name}`, false, []string{"name"}},
+		{"union", `{['kind', 'apiVersion']}`, false, []string{"kind", "apiVersion"}},
+		{"range", `{range .items[0]}{.name}{end}`, false, []string{"items", "name"}},
+		{"duplicates", `{.a.b.a}`, true, []string{"a", "b"}},
+	}
+	for _, test := range analyzeTests {
+		analysis, err := Analyze(test.text)
+		if err != nil {
+			t.Errorf("analyze %s error %v", test.name, err)
+			continue
+		}
+		if analysis.Definite != test.definite {
+			t.Errorf("in %s, expect definite %t, got %t", test.name, test.definite, analysis.Definite)
+		}
+		if !reflect.DeepEqual(analysis.Keys, test.keys) {
+			t.Errorf("in %s, expect keys %v, got %v", test.name, test.keys, analysis.Keys)
+		}
+	}
+
+	if _, err := Analyze(`{.hello`); err == nil {
+		t.Errorf("expect to get an error for an invalid expression")
+	}
+}
@@ -17,6 +17,17 @@ limitations under the License.
 // Package jsonpath is a template engine using jsonpath syntax,
 // which can be seen at http://goessner.net/articles/JsonPath/.
 // In addition, it has {range} {end} function to iterate list and slice.
+// Functions, like {length(@.items)} or {.items[?(@.name.startsWith('kube'))]},
+// can be used in expressions and filters, see FuncMap and Register.
+// The results can be transformed with pipeline stages, like {range .items[*] | sortBy(.name) | limit(10)},
+// the stages are sortBy, unique, limit, reverse and groupBy.
+// The results can be written as text, JSON or YAML, see OutputFormat.
+// Tables like kubectl custom-columns can be written for many documents, see Table and ParseColumns.
+// The {} delimiters can be changed and escaped, see WithDelim and WithEscape.
+// Large inputs can be evaluated without loading them into memory, see JSONPath.Stream.
+// Many documents, e.g. JSON lines or multi-document YAML, can be queried at once, see JSONPath.FindDocuments.
+// Typed values can be extracted with GetString, GetInt, GetBool, GetSlice and Decode.
+// YAML node trees can be queried with source positions of the matches, see JSONPath.FindNodes.
 // This package is copied from repo kubernetes/client-go.
 // See:
 // https://kubernetes.io/docs/reference/kubectl/jsonpath/
@@ -0,0 +1,117 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DocumentFormat identifies how the documents are read from a stream
+type DocumentFormat int
+
+const (
+	// DocumentsJSON reads JSON lines or concatenated JSON values, the default
+	DocumentsJSON DocumentFormat = iota
+	// DocumentsYAML reads multi-document YAML, the documents are separated with ---
+	DocumentsYAML
+)
+
+// DocumentFormatName maps document format code to document format text representation
+var DocumentFormatName = map[DocumentFormat]string{
+	DocumentsJSON: "json",
+	DocumentsYAML: "yaml",
+}
+
+func (f DocumentFormat) String() string {
+	return DocumentFormatName[f]
+}
+
+// ParseDocumentFormat returns the document format with the given text representation
+func ParseDocumentFormat(name string) (DocumentFormat, error) {
+	for format, formatName := range DocumentFormatName {
+		if strings.EqualFold(name, formatName) {
+			return format, nil
+		}
+	}
+	return DocumentsJSON, fmt.Errorf("unknown document format %s", name)
+}
+
+// decoder reads the documents one at a time, it returns io.EOF after the last one
+type decoder interface {
+	Decode(v interface{}) error
+}
+
+func (f DocumentFormat) decoder(r io.Reader) (decoder, error) {
+	switch f {
+	case DocumentsJSON:
+		return json.NewDecoder(r), nil
+	case DocumentsYAML:
+		return yaml.NewDecoder(r), nil
+	}
+	return nil, fmt.Errorf("unknown document format %d", f)
+}
+
+// DocumentResult holds the values matched in one of many documents
+type DocumentResult struct {
+	Document int           // the index of the document, starting from 0
+	Values   []interface{} // the matched values, in order
+}
+
+// DocumentResults holds the values matched in many documents, see FindDocuments
+type DocumentResults []DocumentResult
+
+// Merge returns the values matched in all the documents, in order of the documents
+func (r DocumentResults) Merge() []interface{} {
+	values := []interface{}{}
+	for _, result := range r {
+		values = append(values, result.Values...)
+	}
+	return values
+}
+
+// EachDocument reads the documents from the reader one at a time, bounds every one into template separately,
+// and calls fn with the matched values tagged with the document index, the documents without matches are skipped.
+// Only the current document is kept in memory. Returning an error from fn stops reading and the error is returned.
+func (j *JSONPath) EachDocument(r io.Reader, format DocumentFormat, fn func(result DocumentResult) error) error {
+	decoder, err := format.decoder(r)
+	if err != nil {
+		return err
+	}
+	for i := 0; ; i++ {
+		var document interface{}
+		err := decoder.Decode(&document)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("document %d: %w", i, err)
+		}
+		values, err := j.FindAll(document)
+		if err != nil {
+			return fmt.Errorf("document %d: %w", i, err)
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if err := fn(DocumentResult{Document: i, Values: values}); err != nil {
+			return err
+		}
+	}
+}
+
+// FindDocuments reads the documents from the reader one at a time, bounds every one into template separately,
+// and returns the matched values tagged with the document index, the documents without matches are omitted,
+// see EachDocument
+func (j *JSONPath) FindDocuments(r io.Reader, format DocumentFormat) (DocumentResults, error) {
+	results := DocumentResults{}
+	err := j.EachDocument(r, format, func(result DocumentResult) error {
+		results = append(results, result)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
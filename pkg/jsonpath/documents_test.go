@@ -0,0 +1,120 @@
+package jsonpath
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+const (
+	jsonDocuments = `{"kind": "Pod", "name": "web"}
+{"kind": "Service", "name": "db"}
+{"kind": "Pod", "name": "cache"}{"kind": "ConfigMap"}`
+	yamlDocuments = `kind: Pod
+name: web
+---
+kind: Service
+name: db
+---
+kind: Pod
+name: cache
+---
+kind: ConfigMap
+`
+)
+
+func TestFindDocuments(t *testing.T) {
+	for format, input := range map[DocumentFormat]string{DocumentsJSON: jsonDocuments, DocumentsYAML: yamlDocuments} {
+		results, err := New("{.name}").AllowMissingKeys(true).FindDocuments(strings.NewReader(input), format)
+		if err != nil {
+			t.Errorf("in %s, find error %v", format, err)
+			continue
+		}
+		if out := fmt.Sprint(results); out != "[{0 [web]} {1 [db]} {2 [cache]}]" {
+			t.Errorf("in %s, expect to get [{0 [web]} {1 [db]} {2 [cache]}], got %s", format, out)
+		}
+		if out := fmt.Sprint(results.Merge()); out != "[web db cache]" {
+			t.Errorf("in %s, expect to get [web db cache], got %s", format, out)
+		}
+
+		results, err = New("{.kind}: {.name}").AllowMissingKeys(true).FindDocuments(strings.NewReader(input), format)
+		if err != nil {
+			t.Errorf("in %s, find error %v", format, err)
+			continue
+		}
+		if len(results) != 4 || fmt.Sprint(results[3].Values) != "[ConfigMap : ]" {
+			t.Errorf("in %s, expect to get the text for every document, got %v", format, results)
+		}
+
+		_, err = New("{.name}").FindDocuments(strings.NewReader(input), format)
+		if expect := "document 3: name is not found"; err == nil || err.Error() != expect {
+			t.Errorf("in %s, expect to get error %q, got %v", format, expect, err)
+		}
+	}
+
+	results, err := New("{.name}").FindDocuments(strings.NewReader(""), DocumentsJSON)
+	if err != nil || len(results) != 0 || len(results.Merge()) != 0 {
+		t.Errorf("expect to get no results, got %v, %v", results, err)
+	}
+
+	_, err = New("{.name}").FindDocuments(strings.NewReader(`{"name": "web"} {"name": `), DocumentsJSON)
+	if expect := "document 1: unexpected EOF"; err == nil || err.Error() != expect {
+		t.Errorf("expect to get error %q, got %v", expect, err)
+	}
+	_, err = New("{.name}").FindDocuments(strings.NewReader(""), DocumentFormat(7))
+	if expect := "unknown document format 7"; err == nil || err.Error() != expect {
+		t.Errorf("expect to get error %q, got %v", expect, err)
+	}
+}
+
+// documentReader reads the documents one at a time, counting the reads
+type documentReader struct {
+	documents []string
+	reads     int
+}
+
+func (r *documentReader) Read(p []byte) (int, error) {
+	if len(r.documents) == 0 {
+		return 0, io.EOF
+	}
+	r.reads++
+	n := copy(p, r.documents[0])
+	r.documents[0] = r.documents[0][n:]
+	if r.documents[0] == "" {
+		r.documents = r.documents[1:]
+	}
+	return n, nil
+}
+
+func TestEachDocument(t *testing.T) {
+	reader := &documentReader{documents: []string{`{"name": "web"}`, `{"name": "db"}`, `{"name": "cache"}`}}
+	var names []interface{}
+	stop := fmt.Errorf("stop")
+	err := New("{.name}").EachDocument(reader, DocumentsJSON, func(result DocumentResult) error {
+		names = append(names, result.Values...)
+		if reader.reads != result.Document+1 {
+			t.Errorf("expect to get document %d after %d reads, got %d reads", result.Document, result.Document+1, reader.reads)
+		}
+		if result.Document == 1 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("expect to get error %v, got %v", stop, err)
+	}
+	if fmt.Sprint(names) != "[web db]" {
+		t.Errorf("expect to get [web db], got %v", names)
+	}
+	if len(reader.documents) != 1 {
+		t.Errorf("expect the last document not to be read, got %v left", reader.documents)
+	}
+
+	for format, name := range DocumentFormatName {
+		parsed, err := ParseDocumentFormat(name)
+		if err != nil || parsed != format {
+			t.Errorf("expect to get %v, got %v, %v", format, parsed, err)
+		}
+	}
+}
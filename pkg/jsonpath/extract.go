@@ -0,0 +1,188 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+var (
+	// ErrNoMatch is used to indicate that a single value is expected and the expression matches nothing
+	ErrNoMatch = errors.New("no match")
+	// ErrMultipleMatches is used to indicate that a single value is expected and the expression matches more
+	ErrMultipleMatches = errors.New("multiple matches")
+)
+
+// TypeError describes a matched value that can't be converted to the requested type
+type TypeError struct {
+	Expression string      // the evaluated expression
+	Value      interface{} // the matched value
+	Type       string      // the requested type
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("%s: can't convert %v (%T) to %s", e.Expression, e.Value, e.Value, e.Type)
+}
+
+// FindAll bounds data into template and returns all the matched values,
+// it returns an empty slice when nothing matches
+func (j *JSONPath) FindAll(data interface{}) ([]interface{}, error) {
+	if err := j.Parse(); err != nil {
+		return nil, err
+	}
+	results, err := j.FindResults(data)
+	if err != nil {
+		return nil, err
+	}
+	values := []interface{}{}
+	for _, result := range results {
+		evaluated, err := j.EvalResults(result)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, evaluated...)
+	}
+	return values, nil
+}
+
+// FindOne bounds data into template and returns the only matched value,
+// it returns ErrNoMatch or ErrMultipleMatches when there is not exactly one
+func (j *JSONPath) FindOne(data interface{}) (interface{}, error) {
+	values, err := j.FindAll(data)
+	if err != nil {
+		return nil, err
+	}
+	switch len(values) {
+	case 0:
+		return nil, fmt.Errorf("%s: %w", j.expr, ErrNoMatch)
+	case 1:
+		return values[0], nil
+	default:
+		return nil, fmt.Errorf("%s: %w, got %d", j.expr, ErrMultipleMatches, len(values))
+	}
+}
+
+// Get returns the only value matched by the expression, missing keys are reported as ErrNoMatch
+func Get(expression string, data interface{}) (interface{}, error) {
+	return New(expression).AllowMissingKeys(true).FindOne(data)
+}
+
+// GetAll returns all the values matched by the expression, e.g. {.items[*].name}
+func GetAll(expression string, data interface{}) ([]interface{}, error) {
+	return New(expression).AllowMissingKeys(true).FindAll(data)
+}
+
+// GetString returns the only value matched by the expression, the value must be a string
+func GetString(expression string, data interface{}) (string, error) {
+	value, err := Get(expression, data)
+	if err != nil {
+		return "", err
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", &TypeError{Expression: expression, Value: value, Type: "string"}
+	}
+	return s, nil
+}
+
+// GetInt returns the only value matched by the expression, the value must be an integer number,
+// floating point numbers without a fraction are accepted as decoded from JSON or YAML
+func GetInt(expression string, data interface{}) (int, error) {
+	value, err := Get(expression, data)
+	if err != nil {
+		return 0, err
+	}
+	i, ok := toInt(value)
+	if !ok {
+		return 0, &TypeError{Expression: expression, Value: value, Type: "int"}
+	}
+	return i, nil
+}
+
+// GetBool returns the only value matched by the expression, the value must be a bool
+func GetBool(expression string, data interface{}) (bool, error) {
+	value, err := Get(expression, data)
+	if err != nil {
+		return false, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, &TypeError{Expression: expression, Value: value, Type: "bool"}
+	}
+	return b, nil
+}
+
+// GetSlice returns the only value matched by the expression, the value must be an array or a slice, e.g. {.items};
+// use GetAll to get the values matched by an expression selecting many, e.g. {.items[*]}
+func GetSlice(expression string, data interface{}) ([]interface{}, error) {
+	value, err := Get(expression, data)
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.ValueOf(value)
+	if value == nil || (v.Kind() != reflect.Array && v.Kind() != reflect.Slice) {
+		return nil, &TypeError{Expression: expression, Value: value, Type: "slice"}
+	}
+	s := make([]interface{}, v.Len())
+	for i := range s {
+		s[i] = v.Index(i).Interface()
+	}
+	return s, nil
+}
+
+// Decode decodes the only value matched by the expression into target,
+// the same as json.Unmarshal, so that the json struct tags are respected
+func Decode(expression string, data interface{}, target interface{}) error {
+	value, err := Get(expression, data)
+	if err != nil {
+		return err
+	}
+	return decode(expression, value, target)
+}
+
+// DecodeAll decodes all the values matched by the expression into target, which must point to a slice
+func DecodeAll(expression string, data interface{}, target interface{}) error {
+	values, err := GetAll(expression, data)
+	if err != nil {
+		return err
+	}
+	return decode(expression, values, target)
+}
+
+func decode(expression string, value interface{}, target interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", expression, err)
+	}
+	if err := json.Unmarshal(b, target); err != nil {
+		return fmt.Errorf("%s: %w", expression, err)
+	}
+	return nil
+}
+
+// toInt converts any integer, or a float without a fraction, to int
+func toInt(value interface{}) (int, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := v.Int()
+		return int(i), int64(int(i)) == i
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := v.Uint()
+		return int(u), int(u) >= 0 && uint64(int(u)) == u
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, false
+		}
+		return int(f), true
+	case reflect.String:
+		if n, ok := value.(json.Number); ok {
+			i, err := n.Int64()
+			return int(i), err == nil && int64(int(i)) == i
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,136 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	var input = []byte(`{
+		"name": "cluster",
+		"nodes": 3,
+		"ratio": 0.5,
+		"ready": true,
+		"zones": ["a", "b"],
+		"items": [
+			{"name": "pod1", "port": 80, "labels": {"app": "web"}},
+			{"name": "pod2", "port": 443, "labels": {}}
+		]
+	}`)
+	var data interface{}
+	err := json.Unmarshal(input, &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	extractTests := []struct {
+		name   string
+		get    func() (interface{}, error)
+		expect interface{}
+		err    string
+	}{
+		{"string", func() (interface{}, error) { return GetString("{.name}", data) }, "cluster", ""},
+		{"int", func() (interface{}, error) { return GetInt("{.nodes}", data) }, 3, ""},
+		{"int in struct", func() (interface{}, error) { return GetInt("{.Port}", struct{ Port int8 }{8}) }, 8, ""},
+		{"bool", func() (interface{}, error) { return GetBool("{.ready}", data) }, true, ""},
+		{"slice", func() (interface{}, error) { return GetSlice("{.zones}", data) }, []interface{}{"a", "b"}, ""},
+		{"slice of strings", func() (interface{}, error) { return GetSlice("{.zones}", map[string][]string{"zones": {"c"}}) }, []interface{}{"c"}, ""},
+		{"all", func() (interface{}, error) { return GetAll("{.items[*].name}", data) }, []interface{}{"pod1", "pod2"}, ""},
+		{"all of one", func() (interface{}, error) { return GetAll("{.name}", data) }, []interface{}{"cluster"}, ""},
+		{"all of none", func() (interface{}, error) { return GetAll("{.items[*].status}", data) }, []interface{}{}, ""},
+		{"string mismatch", func() (interface{}, error) { return GetString("{.nodes}", data) }, "",
+			"{.nodes}: can't convert 3 (float64) to string"},
+		{"int mismatch", func() (interface{}, error) { return GetInt("{.ratio}", data) }, 0,
+			"{.ratio}: can't convert 0.5 (float64) to int"},
+		{"bool mismatch", func() (interface{}, error) { return GetBool("{.name}", data) }, false,
+			"{.name}: can't convert cluster (string) to bool"},
+		{"slice mismatch", func() (interface{}, error) { return GetSlice("{.items[0].labels}", data) }, []interface{}(nil),
+			"{.items[0].labels}: can't convert map[app:web] (map[string]interface {}) to slice"},
+		{"no match", func() (interface{}, error) { return GetString("{.version}", data) }, "",
+			"{.version}: no match"},
+		{"multiple matches", func() (interface{}, error) { return GetString("{.items[*].name}", data) }, "",
+			"{.items[*].name}: multiple matches, got 2"},
+		{"invalid expression", func() (interface{}, error) { return GetString("{.name", data) }, "",
+			"unclosed action at line 1, column 7"},
+	}
+	for _, test := range extractTests {
+		out, err := test.get()
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("in %s, expect to get error %q, got %v", test.name, test.err, err)
+			}
+		} else if err != nil {
+			t.Errorf("in %s, extract error %v", test.name, err)
+		}
+		if !reflect.DeepEqual(out, test.expect) {
+			t.Errorf("in %s, expect to get %#v, got %#v", test.name, test.expect, out)
+		}
+	}
+
+	if _, err := GetInt("{.replicas}", data); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("expect to get ErrNoMatch, got %v", err)
+	}
+	if _, err := GetInt("{.items[*].port}", data); !errors.Is(err, ErrMultipleMatches) {
+		t.Errorf("expect to get ErrMultipleMatches, got %v", err)
+	}
+	var typeErr *TypeError
+	if _, err := GetBool("{.nodes}", data); !errors.As(err, &typeErr) || typeErr.Type != "bool" {
+		t.Errorf("expect to get a bool TypeError, got %v", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	var input = []byte(`{
+		"items": [
+			{"name": "pod1", "port": 80, "labels": {"app": "web"}},
+			{"name": "pod2", "port": 443}
+		]
+	}`)
+	var data interface{}
+	err := json.Unmarshal(input, &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type item struct {
+		Name   string            `json:"name"`
+		Port   int               `json:"port"`
+		Labels map[string]string `json:"labels,omitempty"`
+	}
+
+	var one item
+	if err := Decode("{.items[0]}", data, &one); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(one) != "{pod1 80 map[app:web]}" {
+		t.Errorf("expect to get {pod1 80 map[app:web]}, got %v", one)
+	}
+
+	var all []item
+	if err := DecodeAll("{.items[?(@.name == 'pod2')]}", data, &all); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(all) != "[{pod2 443 map[]}]" {
+		t.Errorf("expect to get [{pod2 443 map[]}], got %v", all)
+	}
+
+	var ports []int
+	if err := DecodeAll("{.items[*].port}", data, &ports); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ports) != "[80 443]" {
+		t.Errorf("expect to get [80 443], got %v", ports)
+	}
+
+	if err := Decode("{.items[*]}", data, &one); !errors.Is(err, ErrMultipleMatches) {
+		t.Errorf("expect to get ErrMultipleMatches, got %v", err)
+	}
+	err = Decode("{.items[0].name}", data, &one)
+	expect := "{.items[0].name}: json: cannot unmarshal string into Go value of type jsonpath.item"
+	if err == nil || err.Error() != expect {
+		t.Errorf("expect to get error %q, got %v", expect, err)
+	}
+}
@@ -0,0 +1,215 @@
+package jsonpath
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Format returns the canonical expression text of the tree rooted at node,
+// parsing the text again results in an equivalent tree.
+// The root of a parsed expression holds text and actions, e.g. `hello {.name}`,
+// any other list is formatted as the inside of an action, e.g. `.name`.
+func Format(node Node) string {
+	var b strings.Builder
+	if root, ok := node.(*ListNode); ok && isRoot(root) {
+		formatRoot(&b, root)
+	} else {
+		formatNode(&b, node, nil)
+	}
+	return b.String()
+}
+
+// isRoot reports whether the list holds text and actions
+func isRoot(list *ListNode) bool {
+	if len(list.Nodes) == 0 {
+		return false
+	}
+	for _, node := range list.Nodes {
+		switch node.(type) {
+		case *TextNode, *ListNode:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func formatRoot(b *strings.Builder, root *ListNode) {
+	for _, node := range root.Nodes {
+		switch node := node.(type) {
+		case *TextNode:
+			if strings.Contains(node.Text, leftDelim) {
+				// there is no escape sequence by default, quote it inside of an action instead
+				b.WriteString(leftDelim + strconv.Quote(node.Text) + rightDelim)
+			} else {
+				b.WriteString(node.Text)
+			}
+		case *ListNode:
+			b.WriteString(leftDelim)
+			formatList(b, node)
+			b.WriteString(rightDelim)
+		}
+	}
+}
+
+// formatList writes the steps of a path, or the parts of an action
+func formatList(b *strings.Builder, list *ListNode) {
+	var previous Node
+	for _, node := range list.Nodes {
+		if _, ok := previous.(*IdentifierNode); ok {
+			b.WriteString(" ")
+		}
+		formatNode(b, node, previous)
+		previous = node
+	}
+}
+
+// formatOperand writes a filter operand or a function argument, relative to the current object
+func formatOperand(b *strings.Builder, list *ListNode) {
+	if len(list.Nodes) == 0 || isStep(list.Nodes[0]) {
+		b.WriteString("@")
+	}
+	formatList(b, list)
+}
+
+// isStep reports whether the node selects from the current value
+func isStep(node Node) bool {
+	switch node := node.(type) {
+	case *FieldNode, *ArrayNode, *FilterNode, *WildcardNode, *RecursiveNode, *UnionNode:
+		return true
+	case *FunctionNode:
+		return node.Method
+	}
+	return false
+}
+
+func formatNode(b *strings.Builder, node Node, previous Node) {
+	switch node := node.(type) {
+	case *ListNode:
+		formatList(b, node)
+	case *TextNode:
+		b.WriteString(strconv.Quote(node.Text))
+	case *FieldNode:
+		field := escapeField(node.Value)
+		// the recursive descent operator is followed directly by a field name, e.g. ..name
+		if _, ok := previous.(*RecursiveNode); !ok || field == "" || !isAlphaNumeric([]rune(field)[0]) {
+			b.WriteString(".")
+		}
+		b.WriteString(field)
+	case *IdentifierNode:
+		b.WriteString(node.Name)
+	case *ArrayNode:
+		b.WriteString("[" + formatParams(node.Params) + "]")
+	case *FilterNode:
+		b.WriteString("[?(")
+		formatOperand(b, node.Left)
+		if node.Operator != "exists" {
+			b.WriteString(" " + node.Operator + " ")
+			formatOperand(b, node.Right)
+		}
+		b.WriteString(")]")
+	case *IntNode:
+		b.WriteString(strconv.Itoa(node.Value))
+	case *FloatNode:
+		f := strconv.FormatFloat(node.Value, 'f', -1, 64)
+		if !strings.Contains(f, ".") {
+			// keep it a float when parsed again
+			f += ".0"
+		}
+		b.WriteString(f)
+	case *BoolNode:
+		b.WriteString(strconv.FormatBool(node.Value))
+	case *WildcardNode:
+		b.WriteString(".*")
+	case *RecursiveNode:
+		b.WriteString("..")
+	case *UnionNode:
+		b.WriteString("[")
+		for i, list := range node.Nodes {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			formatUnionItem(b, list)
+		}
+		b.WriteString("]")
+	case *FunctionNode:
+		if node.Method {
+			b.WriteString(".")
+		}
+		b.WriteString(node.Name + "(")
+		for i, arg := range node.Args {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			formatOperand(b, arg)
+		}
+		b.WriteString(")")
+	case *StageNode:
+		b.WriteString(" | " + node.Name)
+		if len(node.Args) > 0 {
+			b.WriteString("(")
+			for i, arg := range node.Args {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				formatOperand(b, arg)
+			}
+			b.WriteString(")")
+		}
+	}
+}
+
+// formatUnionItem writes an array index, a slice or a quoted path
+func formatUnionItem(b *strings.Builder, list *ListNode) {
+	if len(list.Nodes) == 1 {
+		if array, ok := list.Nodes[0].(*ArrayNode); ok {
+			b.WriteString(formatParams(array.Params))
+			return
+		}
+	}
+	var fields []string
+	for _, node := range list.Nodes {
+		if field, ok := node.(*FieldNode); ok {
+			fields = append(fields, field.Value)
+		}
+	}
+	b.WriteString("'" + strings.Join(fields, ".") + "'")
+}
+
+// formatParams writes an array index, or a slice
+func formatParams(params [3]ParamsEntry) string {
+	start, end, step := params[0], params[1], params[2]
+	if !start.Known && !end.Known && !step.Known {
+		return "*"
+	}
+	if end.Derived {
+		return strconv.Itoa(start.Value)
+	}
+	var b strings.Builder
+	if start.Known {
+		b.WriteString(strconv.Itoa(start.Value))
+	}
+	b.WriteString(":")
+	if end.Known {
+		b.WriteString(strconv.Itoa(end.Value))
+	}
+	if step.Known {
+		b.WriteString(":" + strconv.Itoa(step.Value))
+	}
+	return b.String()
+}
+
+// escapeField escapes the characters terminating a field name
+func escapeField(name string) string {
+	if name == "*" {
+		return `\*`
+	}
+	var b strings.Builder
+	for _, r := range name {
+		if isTerminator(r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,63 @@
+package jsonpath
+
+import (
+	"testing"
+)
+
+type formatTest struct {
+	name   string
+	text   string
+	expect string
+}
+
+func TestFormat(t *testing.T) {
+	formatTests := []formatTest{
+		{"plain", `hello jsonpath`, `hello jsonpath`},
+		{"variable", `hello {$.jsonpath}`, `hello {.jsonpath}`},
+		{"array", `{.items[1:3]}`, `{.items[1:3]}`},
+		{"index", `{.items[-1]}`, `{.items[-1]}`},
+		{"slice", `{.items[::2]}`, `{.items[::2]}`},
+		{"allarray", `{.book[*].author}`, `{.book[*].author}`},
+		{"wildcard", `{.bicycle.*}`, `{.bicycle.*}`},
+		{"arraydict", `{['book']}`, `{.book}`},
+		{"escaped", `{.labels.kubernetes\.io/hostname}`, `{.labels.kubernetes\.io/hostname}`},
+		{"filter", `{.items[?(@.price<3)]}`, `{.items[?(@.price < 3)]}`},
+		{"filter float", `{.items[?(@.price>=3.50)]}`, `{.items[?(@.price >= 3.5)]}`},
+		{"filter current", `{[?(@<5)]}`, `{[?(@ < 5)]}`},
+		{"filter exists", `{.items[?(@.isbn)].title}`, `{.items[?(@.isbn)].title}`},
+		{"filter text", `{.users[?(@.name=="e2e")].user.password}`, `{.users[?(@.name == "e2e")].user.password}`},
+		{"filter bool", `{.items[?(@..ready==true)]}`, `{.items[?(@..ready == true)]}`},
+		{"recursive", `{..}`, `{..}`},
+		{"recursive field", `{..price}`, `{..price}`},
+		{"union", `{.items[*]['metadata.name', 'status.capacity']}`, `{.items[*]['metadata.name', 'status.capacity']}`},
+		{"union index", `{[1,3,4]}`, `{[1, 3, 4]}`},
+		{"range", `{range .items[*]}{.name}{"\t"}{end}`, `{range .items[*]}{.name}{"\t"}{end}`},
+		{"quote", `{"{"}`, `{"{"}`},
+		{"function", `{length(.items)}`, `{length(@.items)}`},
+		{"function step", `{.items[?(@.name.startsWith('kube'))].name}`, `{.items[?(@.name.startsWith("kube"))].name}`},
+		{"function arguments", `{contains(@, 'a', 1, 2.0)}`, `{contains(@, "a", 1, 2.0)}`},
+		{"stages", `{range .items[*]|sortBy(.name)|  limit(2)}{.name}{end}`, `{range .items[*] | sortBy(@.name) | limit(2)}{.name}{end}`},
+		{"stage without arguments", `{.items[*].name | unique}`, `{.items[*].name | unique}`},
+	}
+	for _, test := range formatTests {
+		parser, err := Parse(test.name, test.text)
+		if err != nil {
+			t.Errorf("parse %s error %v", test.name, err)
+			continue
+		}
+		out := Format(parser.Root)
+		if out != test.expect {
+			t.Errorf("in %s, expect to get %s, got %s", test.name, test.expect, out)
+		}
+
+		// the canonical text is stable
+		again, err := Parse(test.name, out)
+		if err != nil {
+			t.Errorf("parse formatted %s error %v", test.name, err)
+			continue
+		}
+		if Format(again.Root) != out {
+			t.Errorf("in %s, expect to get %s again, got %s", test.name, out, Format(again.Root))
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package jsonpath
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/VirtusLab/go-extended/pkg/jsonpath/template"
+)
+
+// FuncMap is the type of the map defining the mapping from names to functions.
+// Each function must have either a single return value, or two return values of
+// which the second has type error, the same as in text/template.
+//
+// Functions can be called in expressions and filters, e.g. {length(@.items)}
+// or {.items[?(startsWith(@.name, 'kube'))]}, and as trailing path steps,
+// e.g. {.items.length()}, where the current value is passed as the first argument.
+// An argument matching more than one value is passed as a []interface{}.
+type FuncMap map[string]interface{}
+
+var (
+	errorInterface = reflect.TypeOf((*error)(nil)).Elem()
+
+	functionsMutex sync.RWMutex
+	functions      = FuncMap{
+		"length":     length,
+		"keys":       keys,
+		"min":        minimum,
+		"max":        maximum,
+		"sum":        sum,
+		"avg":        average,
+		"lower":      strings.ToLower,
+		"contains":   containsItem,
+		"startsWith": strings.HasPrefix,
+	}
+)
+
+// Register adds a function available to all expressions, it returns an error
+// if the name is not a valid identifier or the function has an unsupported signature
+func Register(name string, fn interface{}) error {
+	if err := checkFunction(name, fn); err != nil {
+		return err
+	}
+	functionsMutex.Lock()
+	defer functionsMutex.Unlock()
+	functions[name] = fn
+	return nil
+}
+
+// checkFunction verifies the name and the signature of a function
+func checkFunction(name string, fn interface{}) error {
+	if name == "" {
+		return fmt.Errorf("function name is empty")
+	}
+	for i, r := range name {
+		if !isAlphaNumeric(r) || (i == 0 && unicode.IsDigit(r)) {
+			return fmt.Errorf("function name %s is not a valid identifier", name)
+		}
+	}
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return fmt.Errorf("value for %s is not a function", name)
+	}
+	t := v.Type()
+	switch {
+	case t.NumOut() == 1:
+	case t.NumOut() == 2 && t.Out(1) == errorInterface:
+	default:
+		return fmt.Errorf("function %s must return a single value, or a value and an error", name)
+	}
+	return nil
+}
+
+// findFunction looks up a function in the expression functions first and then in the registered functions
+func (j *JSONPath) findFunction(name string) (interface{}, error) {
+	fn, ok := j.funcs[name]
+	if !ok {
+		functionsMutex.RLock()
+		fn, ok = functions[name]
+		functionsMutex.RUnlock()
+	}
+	if !ok {
+		return nil, fmt.Errorf("function %s not defined", name)
+	}
+	if err := checkFunction(name, fn); err != nil {
+		return nil, err
+	}
+	return fn, nil
+}
+
+// evalFunction evaluates FunctionNode for each of the input values
+func (j *JSONPath) evalFunction(input []reflect.Value, node *FunctionNode) ([]reflect.Value, error) {
+	fn, err := j.findFunction(node.Name)
+	if err != nil {
+		return input, err
+	}
+	var results []reflect.Value
+	for _, value := range input {
+		var args []reflect.Value
+		if node.Method {
+			args = append(args, value)
+		}
+		missing := false
+		for _, arg := range node.Args {
+			values, err := j.evalList([]reflect.Value{value}, arg)
+			if err != nil {
+				return input, err
+			}
+			switch len(values) {
+			case 0:
+				missing = true
+			case 1:
+				args = append(args, values[0])
+			default:
+				all := make([]interface{}, len(values))
+				for i := range values {
+					all[i] = values[i].Interface()
+				}
+				args = append(args, reflect.ValueOf(all))
+			}
+		}
+		// there is nothing to call the function with, just like a missing key
+		if missing {
+			continue
+		}
+		result, err := callFunction(node.Name, fn, args)
+		if err != nil {
+			return input, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// callFunction calls the function with the arguments converted to the parameter types
+func callFunction(name string, fn interface{}, args []reflect.Value) (result reflect.Value, err error) {
+	f := reflect.ValueOf(fn)
+	t := f.Type()
+	numIn := t.NumIn()
+	if t.IsVariadic() {
+		if len(args) < numIn-1 {
+			return result, fmt.Errorf("wrong number of arguments for %s: want at least %d got %d", name, numIn-1, len(args))
+		}
+	} else if len(args) != numIn {
+		return result, fmt.Errorf("wrong number of arguments for %s: want %d got %d", name, numIn, len(args))
+	}
+
+	argv := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		var argType reflect.Type
+		if t.IsVariadic() && i >= numIn-1 {
+			argType = t.In(numIn - 1).Elem()
+		} else {
+			argType = t.In(i)
+		}
+		argv[i], err = convertArgument(arg, argType)
+		if err != nil {
+			return result, fmt.Errorf("wrong type for argument %d of %s: %v", i+1, name, err)
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("error calling %s: %v", name, r)
+		}
+	}()
+	out := f.Call(argv)
+	if len(out) == 2 && !out[1].IsNil() {
+		return result, fmt.Errorf("error calling %s: %v", name, out[1].Interface())
+	}
+	return out[0], nil
+}
+
+// convertArgument converts the value to the given parameter type, if possible
+func convertArgument(value reflect.Value, t reflect.Type) (reflect.Value, error) {
+	value, isNil := template.Indirect(value)
+	if isNil || !value.IsValid() {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+			return reflect.Zero(t), nil
+		}
+		return value, fmt.Errorf("nil is not assignable to %s", t)
+	}
+	if value.Type().AssignableTo(t) {
+		return value, nil
+	}
+	if isNumber(value.Kind()) && isNumber(t.Kind()) {
+		return value.Convert(t), nil
+	}
+	return value, fmt.Errorf("%s is not assignable to %s", value.Type(), t)
+}
+
+// isPredicateMet reports whether the values of an exists filter match,
+// a trailing function call returning a bool acts as a predicate
+func isPredicateMet(values []reflect.Value, list *ListNode) bool {
+	if len(list.Nodes) == 0 || len(values) != 1 {
+		return true
+	}
+	if _, ok := list.Nodes[len(list.Nodes)-1].(*FunctionNode); !ok {
+		return true
+	}
+	value, _ := template.Indirect(values[0])
+	if value.Kind() == reflect.Bool {
+		return value.Bool()
+	}
+	return true
+}
+
+// isNumber reports whether the kind is an integer or a floating point number
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// length returns the number of characters of a string or the number of elements of a collection
+func length(v interface{}) (int, error) {
+	value, _ := template.Indirect(reflect.ValueOf(v))
+	switch value.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(value.String()), nil
+	case reflect.Array, reflect.Slice, reflect.Map:
+		return value.Len(), nil
+	}
+	return 0, fmt.Errorf("%v has no length", v)
+}
+
+// keys returns the sorted keys of a map
+func keys(v interface{}) ([]interface{}, error) {
+	value, _ := template.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Map {
+		return nil, fmt.Errorf("%v is not a map", v)
+	}
+	mapKeys := value.MapKeys()
+	sort.Slice(mapKeys, func(a, b int) bool {
+		return fmt.Sprint(mapKeys[a].Interface()) < fmt.Sprint(mapKeys[b].Interface())
+	})
+	results := make([]interface{}, len(mapKeys))
+	for i, key := range mapKeys {
+		results[i] = key.Interface()
+	}
+	return results, nil
+}
+
+// containsItem reports whether a string contains a substring,
+// a collection contains an element or a map contains a key
+func containsItem(collection, item interface{}) (bool, error) {
+	value, _ := template.Indirect(reflect.ValueOf(collection))
+	switch value.Kind() {
+	case reflect.String:
+		s, ok := item.(string)
+		if !ok {
+			return false, fmt.Errorf("%v is not a string", item)
+		}
+		return strings.Contains(value.String(), s), nil
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			element, _ := template.Indirect(value.Index(i))
+			if !element.IsValid() {
+				continue
+			}
+			if isEqual(element, reflect.ValueOf(item)) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case reflect.Map:
+		key := reflect.ValueOf(item)
+		if !key.IsValid() || !key.Type().ConvertibleTo(value.Type().Key()) {
+			return false, nil
+		}
+		return value.MapIndex(key.Convert(value.Type().Key())).IsValid(), nil
+	}
+	return false, fmt.Errorf("%v is not a string, array, slice or map", collection)
+}
+
+// isEqual reports whether the values are equal, numbers are compared regardless of their type
+func isEqual(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return false
+	}
+	if isNumber(a.Kind()) && isNumber(b.Kind()) {
+		floatType := reflect.TypeOf(float64(0))
+		return a.Convert(floatType).Float() == b.Convert(floatType).Float()
+	}
+	equal, err := template.Equal(a.Interface(), b.Interface())
+	return err == nil && equal
+}
+
+// numbers returns a number or a collection of numbers as a slice of floats
+func numbers(v interface{}) ([]float64, error) {
+	value, _ := template.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Array && value.Kind() != reflect.Slice {
+		value = reflect.ValueOf([]interface{}{v})
+	}
+	results := make([]float64, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		element, _ := template.Indirect(value.Index(i))
+		if !element.IsValid() || !isNumber(element.Kind()) {
+			return nil, fmt.Errorf("%v is not a number", value.Index(i))
+		}
+		results[i] = element.Convert(reflect.TypeOf(float64(0))).Float()
+	}
+	return results, nil
+}
+
+// minimum returns the smallest of the numbers
+func minimum(v interface{}) (float64, error) {
+	ns, err := numbers(v)
+	if err != nil {
+		return 0, err
+	}
+	if len(ns) == 0 {
+		return 0, fmt.Errorf("no numbers to compare")
+	}
+	result := ns[0]
+	for _, n := range ns[1:] {
+		if n < result {
+			result = n
+		}
+	}
+	return result, nil
+}
+
+// maximum returns the largest of the numbers
+func maximum(v interface{}) (float64, error) {
+	ns, err := numbers(v)
+	if err != nil {
+		return 0, err
+	}
+	if len(ns) == 0 {
+		return 0, fmt.Errorf("no numbers to compare")
+	}
+	result := ns[0]
+	for _, n := range ns[1:] {
+		if n > result {
+			result = n
+		}
+	}
+	return result, nil
+}
+
+// sum returns the sum of the numbers
+func sum(v interface{}) (float64, error) {
+	ns, err := numbers(v)
+	if err != nil {
+		return 0, err
+	}
+	var result float64
+	for _, n := range ns {
+		result += n
+	}
+	return result, nil
+}
+
+// average returns the arithmetic mean of the numbers
+func average(v interface{}) (float64, error) {
+	ns, err := numbers(v)
+	if err != nil {
+		return 0, err
+	}
+	if len(ns) == 0 {
+		return 0, fmt.Errorf("no numbers to average")
+	}
+	result, _ := sum(ns)
+	return result / float64(len(ns)), nil
+}
@@ -0,0 +1,111 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFunctions(t *testing.T) {
+	var input = []byte(`{
+		"items": [
+			{"name": "kube-proxy", "tags": ["a", "b", "c"], "price": 10, "labels": {"app": "proxy", "tier": "node"}},
+			{"name": "Kube-DNS", "tags": ["b"], "price": 2.5, "labels": {"app": "dns"}},
+			{"name": "etcd", "tags": [], "price": 7.5, "labels": {}}
+		]
+	}`)
+	var data interface{}
+	err := json.Unmarshal(input, &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	functionTests := []jsonpathTest{
+		{"length of array", "{length(@.items)}", data, "3", false},
+		{"length of string", "{.items[0].name.length()}", data, "10", false},
+		{"length step", "{.items[*].tags.length()}", data, "3 1 0", false},
+		{"keys", "{.items[0].labels.keys()}", data, "[app tier]", false},
+		{"min", "{min(@.items[*].price)}", data, "2.5", false},
+		{"max", "{max(@.items[*].price)}", data, "10", false},
+		{"sum", "{sum(@.items[*].price)}", data, "20", false},
+		{"avg", "{avg(@.items[*].price)}", data, "6.666666666666667", false},
+		{"lower", "{.items[1].name.lower()}", data, "kube-dns", false},
+		{"nested", "{lower(@.items[1].name).length()}", data, "8", false},
+		{"contains string", "{contains(@.items[0].name, 'proxy')}", data, "true", false},
+		{"contains array", "{.items[*].tags.contains('b')}", data, "true true false", false},
+		{"contains map", "{.items[*].labels.contains('tier')}", data, "true false false", false},
+		{"startsWith", "{startsWith(@.items[2].name, 'kube')}", data, "false", false},
+		{"filter predicate", "{.items[?(startsWith(@.name, 'kube'))].name}", data, "kube-proxy", false},
+		{"filter step predicate", "{.items[?(@.name.lower().startsWith('kube'))].name}", data, "kube-proxy Kube-DNS", false},
+		{"filter comparison", "{.items[?(length(@.tags) >= 1)].name}", data, "kube-proxy Kube-DNS", false},
+		{"filter comparison with quotes", "{.items[?(contains(@.name, ')') == false)].name}", data, "kube-proxy Kube-DNS etcd", false},
+		{"undefined function", "{nope(@.items)}", data, "", true},
+		{"wrong number of arguments", "{length(@.items, 1)}", data, "", true},
+		{"wrong type", "{lower(@.items)}", data, "", true},
+		{"no length", "{.items[0].price.length()}", data, "", true},
+	}
+	testJSONPath(functionTests, false, t)
+
+	missingKeyTests := []jsonpathTest{
+		{"missing argument", "{.items[*].tier.length()}", data, "", false},
+		{"missing in filter", "{.items[?(@.labels.tier.startsWith('n'))].name}", data, "kube-proxy", false},
+	}
+	testJSONPath(missingKeyTests, true, t)
+}
+
+func TestCustomFunctions(t *testing.T) {
+	data := map[string]interface{}{
+		"words": []interface{}{"hello", "jsonpath"},
+		"count": 2.0,
+	}
+
+	err := Register("upper", strings.ToUpper)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j := New("{.words[*].upper()} {join(@.words, '-')} {repeat('ab', @.count)}")
+	j.Funcs(FuncMap{
+		"join": func(words []interface{}, separator string) string {
+			parts := make([]string, len(words))
+			for i, w := range words {
+				parts[i] = fmt.Sprint(w)
+			}
+			return strings.Join(parts, separator)
+		},
+		"repeat": strings.Repeat,
+	})
+	out, err := j.ExecuteToInterface(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []interface{}{"HELLO", "JSONPATH", " ", "hello-jsonpath", " ", "abab"}
+	if fmt.Sprint(out) != fmt.Sprint(expect) {
+		t.Errorf("expect to get %v, got %v", expect, out)
+	}
+
+	failRegisterTests := []struct {
+		name string
+		fn   interface{}
+		err  string
+	}{
+		{"", strings.ToUpper, "function name is empty"},
+		{"1st", strings.ToUpper, "function name 1st is not a valid identifier"},
+		{"not-valid", strings.ToUpper, "function name not-valid is not a valid identifier"},
+		{"notFunc", "upper", "value for notFunc is not a function"},
+		{"noResult", func() {}, "function noResult must return a single value, or a value and an error"},
+	}
+	for _, test := range failRegisterTests {
+		err := Register(test.name, test.fn)
+		var out string
+		if err == nil {
+			out = "nil"
+		} else {
+			out = err.Error()
+		}
+		if out != test.err {
+			t.Errorf("in %s, expect to get error %q, got %q", test.name, test.err, out)
+		}
+	}
+}
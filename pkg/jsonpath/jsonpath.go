@@ -38,16 +38,21 @@ type JSONPath struct {
 	endRange   int
 
 	allowMissingKeys bool
+	funcs            FuncMap
+	output           OutputFormat
+	parserOptions    []func(*Parser)
 }
 
-// New creates a new JSONPath with the given name.
-func New(expression string) *JSONPath {
+// New creates a new JSONPath with the given expression and zero or more parser options,
+// see WithDelim and WithEscape
+func New(expression string, options ...func(*Parser)) *JSONPath {
 	return &JSONPath{
-		expr:       expression,
-		name:       "jsonpath",
-		beginRange: 0,
-		inRange:    0,
-		endRange:   0,
+		expr:          expression,
+		name:          "jsonpath",
+		beginRange:    0,
+		inRange:       0,
+		endRange:      0,
+		parserOptions: options,
 	}
 }
 
@@ -58,10 +63,30 @@ func (j *JSONPath) AllowMissingKeys(allow bool) *JSONPath {
 	return j
 }
 
+// Funcs adds the elements of the argument map to the functions available to the expression,
+// they take precedence over the registered functions with the same name, see also Register.
+// The receiver is returned for chaining.
+func (j *JSONPath) Funcs(funcs FuncMap) *JSONPath {
+	if j.funcs == nil {
+		j.funcs = FuncMap{}
+	}
+	for name, fn := range funcs {
+		j.funcs[name] = fn
+	}
+	return j
+}
+
+// Output sets the format used to write the results, see OutputFormat.
+// The receiver is returned for chaining.
+func (j *JSONPath) Output(format OutputFormat) *JSONPath {
+	j.output = format
+	return j
+}
+
 // Parse parses the given expression or returns an error
 func (j *JSONPath) Parse() error {
 	var err error
-	j.parser, err = Parse(j.name, j.expr)
+	j.parser, err = Parse(j.name, j.expr, j.parserOptions...)
 	return err
 }
 
@@ -71,12 +96,12 @@ func (j *JSONPath) Execute(wr io.Writer, data interface{}) error {
 	if err != nil {
 		return err
 	}
-	fullResults, err := j.FindResults(data)
+	fullResults, literals, err := j.findResults(data)
 	if err != nil {
 		return err
 	}
 	for ix := range fullResults {
-		if err := j.PrintResults(wr, fullResults[ix]); err != nil {
+		if err := j.printResults(wr, fullResults[ix], literals[ix]); err != nil {
 			return err
 		}
 	}
@@ -85,18 +110,25 @@ func (j *JSONPath) Execute(wr io.Writer, data interface{}) error {
 
 // FindResults searches recursively the data evaluating the path expression
 func (j *JSONPath) FindResults(data interface{}) ([][]reflect.Value, error) {
+	results, _, err := j.findResults(data)
+	return results, err
+}
+
+// findResults is FindResults also reporting which results are the literal text of the template, see isLiteral
+func (j *JSONPath) findResults(data interface{}) ([][]reflect.Value, []bool, error) {
 	if j.parser == nil {
-		return nil, fmt.Errorf("%s is an incomplete jsonpath template", j.name)
+		return nil, nil, fmt.Errorf("%s is an incomplete jsonpath template", j.name)
 	}
 
 	j.cur = []reflect.Value{reflect.ValueOf(data)}
 	nodes := j.parser.Root.Nodes
 	var fullResult [][]reflect.Value
+	var literals []bool
 	for i := 0; i < len(nodes); i++ {
 		node := nodes[i]
 		results, err := j.walk(j.cur, node)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// encounter an end node, break the current block
@@ -113,21 +145,36 @@ func (j *JSONPath) FindResults(data interface{}) ([][]reflect.Value, error) {
 				if k == len(results)-1 {
 					j.inRange--
 				}
-				nextResults, err := j.FindResults(value.Interface())
+				nextResults, nextLiterals, err := j.findResults(value.Interface())
 				if err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 				fullResult = append(fullResult, nextResults...)
+				literals = append(literals, nextLiterals...)
 			}
 			break
 		}
 		fullResult = append(fullResult, results)
+		literals = append(literals, isLiteral(node))
 	}
-	return fullResult, nil
+	return fullResult, literals, nil
 }
 
-// PrintResults writes the results into writer
+// PrintResults writes the results into writer using the output format, see Output.
+// Execute writes the text of the template as is instead.
 func (j *JSONPath) PrintResults(wr io.Writer, results []reflect.Value) error {
+	return j.printResults(wr, results, false)
+}
+
+// printResults writes the results using the output format, or as is if they are the literal text of the template
+func (j *JSONPath) printResults(wr io.Writer, results []reflect.Value, literal bool) error {
+	if j.output != OutputText && !literal {
+		return j.printStructured(wr, results)
+	}
+	if j.output == OutputJSONLines {
+		// every line is a JSON value, there is no place for the text in between
+		return nil
+	}
 	for i, r := range results {
 		text, err := j.EvalToText(r)
 		if err != nil {
@@ -170,6 +217,10 @@ func (j *JSONPath) walk(value []reflect.Value, node Node) ([]reflect.Value, erro
 		return j.evalUnion(value, node)
 	case *IdentifierNode:
 		return j.evalIdentifier(value, node)
+	case *FunctionNode:
+		return j.evalFunction(value, node)
+	case *StageNode:
+		return j.evalStage(value, node)
 	default:
 		return value, fmt.Errorf("unexpected Node %v", node)
 	}
@@ -252,49 +303,80 @@ func (j *JSONPath) evalArray(input []reflect.Value, node *ArrayNode) ([]reflect.
 		if value.Kind() != reflect.Array && value.Kind() != reflect.Slice {
 			return input, fmt.Errorf("%v is not array or slice", value.Type())
 		}
-		params := node.Params
-		if !params[0].Known {
-			params[0].Value = 0
+		indexes, err := arrayIndexes(node.Params, value.Len())
+		if err != nil {
+			return input, err
 		}
-		if params[0].Value < 0 {
-			params[0].Value += value.Len()
+		for _, i := range indexes {
+			result = append(result, value.Index(i))
 		}
-		if !params[1].Known {
-			params[1].Value = value.Len()
+	}
+	return result, nil
+}
+
+// arrayIndexes returns the indexes selected by an index or a slice from an array of the given length.
+// The slices follow RFC 9535: negative bounds count from the end, the bounds are clamped to the array,
+// and a negative step iterates in reverse, e.g. [::-1] selects all the elements from the last one.
+func arrayIndexes(params [3]ParamsEntry, length int) ([]int, error) {
+	if params[1].Derived {
+		i := normalizeIndex(params[0].Value, length)
+		if i < 0 || i >= length {
+			return nil, fmt.Errorf("array index out of bounds: index %d, length %d", params[0].Value, length)
 		}
+		return []int{i}, nil
+	}
 
-		if params[1].Value < 0 || (params[1].Value == 0 && params[1].Derived) {
-			params[1].Value += value.Len()
+	step := 1
+	if params[2].Known {
+		step = params[2].Value
+	}
+	var indexes []int
+	switch {
+	case step > 0:
+		lower, upper := 0, length
+		if params[0].Known {
+			lower = clampIndex(normalizeIndex(params[0].Value, length), 0, length)
 		}
-		sliceLength := value.Len()
-		if params[1].Value != params[0].Value { // if you're requesting zero elements, allow it through.
-			if params[0].Value >= sliceLength || params[0].Value < 0 {
-				return input, fmt.Errorf("array index out of bounds: index %d, length %d", params[0].Value, sliceLength)
-			}
-			if params[1].Value > sliceLength || params[1].Value < 0 {
-				return input, fmt.Errorf("array index out of bounds: index %d, length %d", params[1].Value-1, sliceLength)
-			}
-			if params[0].Value > params[1].Value {
-				return input, fmt.Errorf("starting index %d is greater than ending index %d", params[0].Value, params[1].Value)
-			}
-		} else {
-			return result, nil
+		if params[1].Known {
+			upper = clampIndex(normalizeIndex(params[1].Value, length), 0, length)
 		}
-
-		value = value.Slice(params[0].Value, params[1].Value)
-
-		step := 1
-		if params[2].Known {
-			if params[2].Value <= 0 {
-				return input, fmt.Errorf("step must be > 0")
-			}
-			step = params[2].Value
+		for i := lower; i < upper; i += step {
+			indexes = append(indexes, i)
 		}
-		for i := 0; i < value.Len(); i += step {
-			result = append(result, value.Index(i))
+	case step < 0:
+		upper, lower := length-1, -1
+		if params[0].Known {
+			upper = clampIndex(normalizeIndex(params[0].Value, length), -1, length-1)
+		}
+		if params[1].Known {
+			lower = clampIndex(normalizeIndex(params[1].Value, length), -1, length-1)
 		}
+		for i := upper; i > lower; i += step {
+			indexes = append(indexes, i)
+		}
+	default:
+		return nil, fmt.Errorf("array slice step can't be 0")
 	}
-	return result, nil
+	return indexes, nil
+}
+
+// normalizeIndex converts an index counted from the end of the array, e.g. -1 for the last element
+func normalizeIndex(i, length int) int {
+	if i < 0 {
+		return length + i
+	}
+	return i
+}
+
+// clampIndex limits the index to the range between lower and upper inclusive
+func clampIndex(i, lower, upper int) int {
+	if i < lower {
+		return lower
+	}
+	if i > upper {
+		return upper
+	}
+	return i
 }
 
 // evalUnion evaluates UnionNode
@@ -462,7 +544,7 @@ func (j *JSONPath) evalFilter(input []reflect.Value, node *FilterNode) ([]reflec
 
 			//case exists
 			if node.Operator == "exists" {
-				if len(lefts) > 0 {
+				if len(lefts) > 0 && isPredicateMet(lefts, node.Left) {
 					results = append(results, value.Index(i))
 				}
 				continue
@@ -198,6 +198,32 @@ func TestStructInput(t *testing.T) {
 	testFailJSONPath(failStoreTests, t)
 }
 
+func TestDelims(t *testing.T) {
+	data := map[string]interface{}{
+		"name":  "jsonpath",
+		"items": []interface{}{"a", "b"},
+	}
+	j := New(`{"name": "{{.name}}", "items": [{{range .items[*]}}"{{@}}", {{end}}]}`, WithDelim("{{", "}}"))
+	buf := new(bytes.Buffer)
+	if err := j.Execute(buf, data); err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"name": "jsonpath", "items": ["a", "b", ]}`
+	if buf.String() != expect {
+		t.Errorf(`expect to get "%s", got "%s"`, expect, buf.String())
+	}
+
+	j = New(`\{"name": "{.name}"\}`, WithEscape(`\`))
+	buf = new(bytes.Buffer)
+	if err := j.Execute(buf, data); err != nil {
+		t.Fatal(err)
+	}
+	expect = `{"name": "jsonpath"}`
+	if buf.String() != expect {
+		t.Errorf(`expect to get "%s", got "%s"`, expect, buf.String())
+	}
+}
+
 func TestJSONInput(t *testing.T) {
 	var pointsJSON = []byte(`[
 		{"id": "i1", "x":4, "y":-5},
@@ -427,11 +453,11 @@ func TestNegativeIndex(t *testing.T) {
 				false,
 			},
 			{
-				"test containers[-1:0], expect error",
+				"test containers[-1:0], it equals containers[3:0], expect empty set",
 				`{.spec.containers[-1:0].name}`,
 				data,
 				"",
-				true,
+				false,
 			},
 			{
 				"test containers[-1], it equals containers[3]",
@@ -504,18 +530,18 @@ func TestNegativeIndex(t *testing.T) {
 				false,
 			},
 			{
-				"test containers[3:1], expect a error cause start index is greater than end index",
+				"test containers[3:1], expect empty set cause start index is greater than end index",
 				`{.spec.containers[3:1].name}`,
 				data,
 				"",
-				true,
+				false,
 			},
 			{
-				"test containers[-1:-2], it equals containers[3:2], expect a error cause start index is greater than end index",
+				"test containers[-1:-2], it equals containers[3:2], expect empty set cause start index is greater than end index",
 				`{.spec.containers[-1:-2].name}`,
 				data,
 				"",
-				true,
+				false,
 			},
 		},
 		false,
@@ -592,11 +618,11 @@ func TestStep(t *testing.T) {
 				true,
 			},
 			{
-				"test containers[0:6:-1], it errors",
+				"test containers[0:6:-1], expect empty set cause start index is lower than end index",
 				`{.spec.containers[0:6:-1].name}`,
 				data,
 				"",
-				true,
+				false,
 			},
 			{
 				"test containers[1:4:2]",
@@ -652,3 +678,55 @@ func TestStep(t *testing.T) {
 		t,
 	)
 }
+
+func TestSlice(t *testing.T) {
+	data := []interface{}{"a", "b", "c", "d", "e", "f", "g"}
+
+	// the examples of RFC 9535, section 2.3.4.3, followed by the bounds and steps edge cases
+	testJSONPath(
+		[]jsonpathTest{
+			{"index", `{[1]}`, data, "b", false},
+			{"negative index", `{[-2]}`, data, "f", false},
+			{"slice", `{[1:3]}`, data, "b c", false},
+			{"slice with no end index", `{[5:]}`, data, "f g", false},
+			{"slice with step 2", `{[1:5:2]}`, data, "b d", false},
+			{"slice with negative step", `{[5:1:-2]}`, data, "f d", false},
+			{"slice in reverse order", `{[::-1]}`, data, "g f e d c b a", false},
+			{"negative bounds", `{[-3:-1]}`, data, "e f", false},
+			{"negative bounds in reverse order", `{[-1:-4:-1]}`, data, "g f e", false},
+			{"start clamped", `{[-10:2]}`, data, "a b", false},
+			{"end clamped", `{[4:100]}`, data, "e f g", false},
+			{"start clamped in reverse order", `{[100:4:-1]}`, data, "g f", false},
+			{"end clamped in reverse order", `{[1:-100:-1]}`, data, "b a", false},
+			{"start out of bounds", `{[10:]}`, data, "", false},
+			{"end before start", `{[5:1]}`, data, "", false},
+			{"start before end in reverse order", `{[1:5:-1]}`, data, "", false},
+			{"every second in reverse order", `{[::-2]}`, data, "g e c a", false},
+			{"step larger than length", `{[::10]}`, data, "a", false},
+			{"index out of bounds", `{[7]}`, data, "", true},
+			{"negative index out of bounds", `{[-8]}`, data, "", true},
+			{"step 0", `{[::0]}`, data, "", true},
+		},
+		false,
+		t,
+	)
+}
+
+func TestIndexOutOfBounds(t *testing.T) {
+	data := []interface{}{"a", "b", "c"}
+	for _, allowMissingKeys := range []bool{false, true} {
+		for template, expect := range map[string]string{
+			`{[3]}`:  "array index out of bounds: index 3, length 3",
+			`{[-4]}`: "array index out of bounds: index -4, length 3",
+		} {
+			j := New(template).AllowMissingKeys(allowMissingKeys)
+			if err := j.Parse(); err != nil {
+				t.Fatal(err)
+			}
+			err := j.Execute(new(bytes.Buffer), data)
+			if err == nil || err.Error() != expect {
+				t.Errorf("in %s with allowMissingKeys %v, expect to get error %q, got %v", template, allowMissingKeys, expect, err)
+			}
+		}
+	}
+}
@@ -55,6 +55,10 @@ const (
 	NodeUnion
 	// NodeBool is a boolean node type code
 	NodeBool
+	// NodeFunction is a function call node type code
+	NodeFunction
+	// NodeStage is a pipeline stage node type code
+	NodeStage
 )
 
 // NodeTypeName maps node type code to node type text representation
@@ -71,6 +75,8 @@ var NodeTypeName = map[NodeType]string{
 	NodeRecursive:  "NodeRecursive",
 	NodeUnion:      "NodeUnion",
 	NodeBool:       "NodeBool",
+	NodeFunction:   "NodeFunction",
+	NodeStage:      "NodeStage",
 }
 
 // Node represents a parse tree node
@@ -268,3 +274,43 @@ func newBool(value bool) *BoolNode {
 func (b *BoolNode) String() string {
 	return fmt.Sprintf("%s: %t", b.Type(), b.Value)
 }
+
+// FunctionNode holds a function call with its arguments
+type FunctionNode struct {
+	NodeType
+	Name   string
+	Args   []*ListNode
+	Method bool // whether the function is called as a path step on the current value
+}
+
+func newFunction(name string, args []*ListNode, method bool) *FunctionNode {
+	return &FunctionNode{
+		NodeType: NodeFunction,
+		Name:     name,
+		Args:     args,
+		Method:   method,
+	}
+}
+
+func (f *FunctionNode) String() string {
+	return fmt.Sprintf("%s: %s", f.Type(), f.Name)
+}
+
+// StageNode holds a pipeline stage applied to the whole result set, e.g. | sortBy(.name)
+type StageNode struct {
+	NodeType
+	Name string
+	Args []*ListNode
+}
+
+func newStage(name string, args []*ListNode) *StageNode {
+	return &StageNode{
+		NodeType: NodeStage,
+		Name:     name,
+		Args:     args,
+	}
+}
+
+func (s *StageNode) String() string {
+	return fmt.Sprintf("%s: %s", s.Type(), s.Name)
+}
@@ -0,0 +1,126 @@
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OutputFormat identifies how the results are written
+type OutputFormat int
+
+const (
+	// OutputText writes the results as plain text separated with spaces, the default
+	OutputText OutputFormat = iota
+	// OutputJSON writes the results as compact JSON, many results as an array
+	OutputJSON
+	// OutputPrettyJSON writes the results as indented JSON, many results as an array
+	OutputPrettyJSON
+	// OutputYAML writes the results as YAML, many results as a sequence
+	OutputYAML
+	// OutputJSONLines writes every result as compact JSON in a separate line, the text is omitted
+	OutputJSONLines
+)
+
+// OutputFormatName maps output format code to output format text representation
+var OutputFormatName = map[OutputFormat]string{
+	OutputText:       "text",
+	OutputJSON:       "json",
+	OutputPrettyJSON: "pretty-json",
+	OutputYAML:       "yaml",
+	OutputJSONLines:  "jsonl",
+}
+
+func (f OutputFormat) String() string {
+	return OutputFormatName[f]
+}
+
+// ParseOutputFormat returns the output format with the given text representation
+func ParseOutputFormat(name string) (OutputFormat, error) {
+	for format, formatName := range OutputFormatName {
+		if strings.EqualFold(name, formatName) {
+			return format, nil
+		}
+	}
+	return OutputText, fmt.Errorf("unknown output format %s", name)
+}
+
+// isLiteral reports whether the node evaluates to the literal text, outside of the actions or quoted inside of them,
+// it is written as is regardless of the output format
+func isLiteral(node Node) bool {
+	switch node := node.(type) {
+	case *TextNode:
+		return true
+	case *ListNode:
+		if len(node.Nodes) == 0 {
+			return false
+		}
+		for _, n := range node.Nodes {
+			if _, ok := n.(*TextNode); !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// printStructured writes the results using one of the structured output formats
+func (j *JSONPath) printStructured(wr io.Writer, results []reflect.Value) error {
+	if len(results) == 0 {
+		return nil
+	}
+	values, err := j.EvalResults(results)
+	if err != nil {
+		return err
+	}
+	var value interface{} = values
+	if len(values) == 1 {
+		value = values[0]
+	}
+
+	var buffer bytes.Buffer
+	switch j.output {
+	case OutputJSON, OutputPrettyJSON:
+		indent := ""
+		if j.output == OutputPrettyJSON {
+			indent = "  "
+		}
+		// the trailing newline is up to the template
+		if err = encodeJSON(&buffer, value, indent); err == nil {
+			buffer.Truncate(buffer.Len() - 1)
+		}
+	case OutputYAML:
+		encoder := yaml.NewEncoder(&buffer)
+		encoder.SetIndent(2)
+		if err = encoder.Encode(value); err == nil {
+			err = encoder.Close()
+		}
+	case OutputJSONLines:
+		for _, v := range values {
+			if err = encodeJSON(&buffer, v, ""); err != nil {
+				break
+			}
+		}
+	default:
+		return fmt.Errorf("unknown output format %d", j.output)
+	}
+	if err != nil {
+		return fmt.Errorf("can't write the results as %s: %v", j.output, err)
+	}
+	_, err = wr.Write(buffer.Bytes())
+	return err
+}
+
+// encodeJSON writes the value as JSON followed by a newline, without escaping HTML characters
+func encodeJSON(wr io.Writer, value interface{}, indent string) error {
+	encoder := json.NewEncoder(wr)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+	return encoder.Encode(value)
+}
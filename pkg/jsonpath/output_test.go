@@ -0,0 +1,100 @@
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type outputTest struct {
+	name     string
+	template string
+	output   OutputFormat
+	expect   string
+}
+
+func TestOutput(t *testing.T) {
+	var input = []byte(`{
+		"items": [
+			{"metadata": {"name": "pod1", "labels": {"tier": "web", "app": "<a&b>"}}, "ports": [80, 443]},
+			{"metadata": {"name": "pod2", "labels": {}}, "ports": []}
+		]
+	}`)
+	var data interface{}
+	err := json.Unmarshal(input, &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outputTests := []outputTest{
+		{"text", `{.items[0].metadata}`, OutputText,
+			"map[labels:map[app:<a&b> tier:web] name:pod1]"},
+		{"json", `{.items[0].metadata}`, OutputJSON,
+			`{"labels":{"app":"<a&b>","tier":"web"},"name":"pod1"}`},
+		{"json many", `{.items[*].metadata.name}`, OutputJSON,
+			`["pod1","pod2"]`},
+		{"json range", `{range .items[*]}{.metadata.name}: {.ports}{"\n"}{end}`, OutputJSON,
+			"\"pod1\": [80,443]\n\"pod2\": []\n"},
+		{"pretty json", `{.items[0].ports}`, OutputPrettyJSON,
+			"[\n  80,\n  443\n]"},
+		{"yaml", `{.items[0].metadata}`, OutputYAML,
+			"labels:\n  app: <a&b>\n  tier: web\nname: pod1\n"},
+		{"yaml many", `{.items[*].metadata.name}`, OutputYAML,
+			"- pod1\n- pod2\n"},
+		{"json lines", `{.items[*].metadata.name}`, OutputJSONLines,
+			"\"pod1\"\n\"pod2\"\n"},
+		{"json lines range", `{range .items[*]}{.metadata.labels}{"\n"}{end}`, OutputJSONLines,
+			"{\"app\":\"<a&b>\",\"tier\":\"web\"}\n{}\n"},
+	}
+	for _, test := range outputTests {
+		buf := new(bytes.Buffer)
+		err := New(test.template).Output(test.output).Execute(buf, data)
+		if err != nil {
+			t.Errorf("in %s, execute error %v", test.name, err)
+			continue
+		}
+		if buf.String() != test.expect {
+			t.Errorf("in %s, expect to get %q, got %q", test.name, test.expect, buf.String())
+		}
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	for format, name := range OutputFormatName {
+		parsed, err := ParseOutputFormat(name)
+		if err != nil {
+			t.Errorf("parse %s error %v", name, err)
+		}
+		if parsed != format {
+			t.Errorf("expect to get %v, got %v", format, parsed)
+		}
+	}
+	if _, err := ParseOutputFormat("xml"); err == nil || err.Error() != "unknown output format xml" {
+		t.Errorf("expect to get error %q, got %v", "unknown output format xml", err)
+	}
+}
+
+func TestFindResultsText(t *testing.T) {
+	j := New(`name: {.name}{"\n"}`).Output(OutputJSON)
+	if err := j.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	results, err := j.FindResults(map[string]interface{}{"name": "pod1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	for _, result := range results {
+		for _, value := range result {
+			text, ok := value.Interface().(string)
+			if !ok {
+				t.Fatalf("expect to get a string, got %T", value.Interface())
+			}
+			texts = append(texts, text)
+		}
+	}
+	if expect := []string{"name: ", "pod1", "\n"}; !reflect.DeepEqual(texts, expect) {
+		t.Errorf("expect to get %q, got %q", expect, texts)
+	}
+}
@@ -35,12 +35,15 @@ const (
 
 // Parser represents the JSONPath flavour syntax parser
 type Parser struct {
-	Name  string
-	Root  *ListNode
-	input string
-	pos   int
-	start int
-	width int
+	Name       string
+	Root       *ListNode
+	input      string
+	pos        int
+	start      int
+	width      int
+	leftDelim  string
+	rightDelim string
+	escape     string
 }
 
 var (
@@ -50,11 +53,63 @@ var (
 	sliceOperatorRex = regexp.MustCompile(`^(-?[\d]*)(:-?[\d]*)?(:-?[\d]*)?$`)
 )
 
+// ParseError describes a syntax error and its position in the expression
+type ParseError struct {
+	Expression string // the whole parsed text
+	Offset     int    // byte offset of the failing character
+	RuneOffset int    // rune offset of the failing character
+	Line       int    // line number of the failing character, starting at 1
+	Column     int    // column (in runes) of the failing character, starting at 1
+	Message    string // description of the error
+}
+
+func newParseError(expression string, offset int, message string) *ParseError {
+	if offset < 0 {
+		offset = 0
+	} else if offset > len(expression) {
+		offset = len(expression)
+	}
+	before := expression[:offset]
+	lineStart := strings.LastIndex(before, "\n") + 1
+	return &ParseError{
+		Expression: expression,
+		Offset:     offset,
+		RuneOffset: utf8.RuneCountInString(before),
+		Line:       strings.Count(before, "\n") + 1,
+		Column:     utf8.RuneCountInString(before[lineStart:]) + 1,
+		Message:    message,
+	}
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at line %d, column %d", e.Message, e.Line, e.Column)
+}
+
+// Snippet returns the line of the expression with the error and a caret under the failing character
+func (e *ParseError) Snippet() string {
+	lines := strings.Split(e.Expression, "\n")
+	line := strings.TrimRight(lines[e.Line-1], "\r")
+	var caret strings.Builder
+	for i, r := range []rune(line) {
+		if i >= e.Column-1 {
+			break
+		}
+		// keep the tabs to align the caret
+		if r == '\t' {
+			caret.WriteRune('\t')
+		} else {
+			caret.WriteRune(' ')
+		}
+	}
+	caret.WriteRune('^')
+	return line + "\n" + caret.String()
+}
+
 // Parse parses the given text and return a node Parser.
 // If an error is encountered, parsing stops and an empty
 // Parser is returned with the error
-func Parse(name, text string) (*Parser, error) {
-	p := NewParser(name)
+func Parse(name, text string, options ...func(*Parser)) (*Parser, error) {
+	p := NewParser(name, options...)
 	err := p.Parse(text)
 	if err != nil {
 		p = nil
@@ -62,10 +117,33 @@ func Parse(name, text string) (*Parser, error) {
 	return p, err
 }
 
-// NewParser creates a new Parser
-func NewParser(name string) *Parser {
-	return &Parser{
-		Name: name,
+// NewParser creates a new Parser with zero or more options, see WithDelim and WithEscape
+func NewParser(name string, options ...func(*Parser)) *Parser {
+	p := &Parser{
+		Name:       name,
+		leftDelim:  leftDelim,
+		rightDelim: rightDelim,
+	}
+	for _, o := range options {
+		o(p)
+	}
+	return p
+}
+
+// WithDelim mutates Parser configuration with new left and right delimiters
+func WithDelim(left, right string) func(*Parser) {
+	return func(p *Parser) {
+		p.leftDelim = left
+		p.rightDelim = right
+	}
+}
+
+// WithEscape mutates Parser configuration with an escape sequence for the text outside of actions,
+// the escape followed by a delimiter or by the escape itself is replaced with the latter,
+// e.g. with WithEscape(`\`) the text `\{` becomes `{`; there is no escape sequence by default
+func WithEscape(escape string) func(*Parser) {
+	return func(p *Parser) {
+		p.escape = escape
 	}
 }
 
@@ -80,8 +158,27 @@ func parseAction(name, text string) (*Parser, error) {
 	return p, nil
 }
 
+// errorf returns a ParseError for the character at the given byte offset of the input
+func (p *Parser) errorf(offset int, format string, args ...interface{}) error {
+	return newParseError(p.input, offset, fmt.Sprintf(format, args...))
+}
+
+// rebase moves the error position of a nested action parsed from the given byte offset of the input
+func (p *Parser) rebase(err error, offset int) error {
+	if e, ok := err.(*ParseError); ok {
+		return newParseError(p.input, offset+e.Offset-len(leftDelim), e.Message)
+	}
+	return err
+}
+
 // Parse parses the given text
 func (p *Parser) Parse(text string) error {
+	if len(p.leftDelim) == 0 {
+		return errors.New("unexpected empty left delimiter")
+	}
+	if len(p.rightDelim) == 0 {
+		return errors.New("unexpected empty right delimiter")
+	}
 	p.input = text
 	p.Root = newList()
 	p.pos = 0
@@ -120,10 +217,20 @@ func (p *Parser) backup() {
 }
 
 func (p *Parser) parseText(cur *ListNode) error {
+	var text strings.Builder
 	for {
-		if strings.HasPrefix(p.input[p.pos:], leftDelim) {
-			if p.pos > p.start {
-				cur.append(newText(p.consumeText()))
+		if escaped := p.escaped(); escaped != "" {
+			// skip the escape and keep the escaped text
+			text.WriteString(p.consumeText())
+			p.pos += len(p.escape)
+			p.consumeText()
+			p.pos += len(escaped)
+			continue
+		}
+		if strings.HasPrefix(p.input[p.pos:], p.leftDelim) {
+			text.WriteString(p.consumeText())
+			if text.Len() > 0 {
+				cur.append(newText(text.String()))
 			}
 			return p.parseLeftDelim(cur)
 		}
@@ -132,15 +239,30 @@ func (p *Parser) parseText(cur *ListNode) error {
 		}
 	}
 	// Correctly reached EOF.
-	if p.pos > p.start {
-		cur.append(newText(p.consumeText()))
+	text.WriteString(p.consumeText())
+	if text.Len() > 0 {
+		cur.append(newText(text.String()))
 	}
 	return nil
 }
 
+// escaped returns the delimiter or the escape following the escape at the current position, if any
+func (p *Parser) escaped() string {
+	if len(p.escape) == 0 || !strings.HasPrefix(p.input[p.pos:], p.escape) {
+		return ""
+	}
+	rest := p.input[p.pos+len(p.escape):]
+	for _, escaped := range []string{p.leftDelim, p.rightDelim, p.escape} {
+		if strings.HasPrefix(rest, escaped) {
+			return escaped
+		}
+	}
+	return ""
+}
+
 // parseLeftDelim scans the left delimiter, which is known to be present.
 func (p *Parser) parseLeftDelim(cur *ListNode) error {
-	p.pos += len(leftDelim)
+	p.pos += len(p.leftDelim)
 	p.consumeText()
 	newNode := newList()
 	cur.append(newNode)
@@ -150,9 +272,9 @@ func (p *Parser) parseLeftDelim(cur *ListNode) error {
 
 func (p *Parser) parseInsideAction(cur *ListNode) error {
 	prefixMap := map[string]func(*ListNode) error{
-		rightDelim: p.parseRightDelim,
-		"[?(":      p.parseFilter,
-		"..":       p.parseRecursive,
+		p.rightDelim: p.parseRightDelim,
+		"[?(":        p.parseFilter,
+		"..":         p.parseRecursive,
 	}
 	for prefix, parseFunc := range prefixMap {
 		if strings.HasPrefix(p.input[p.pos:], prefix) {
@@ -162,13 +284,15 @@ func (p *Parser) parseInsideAction(cur *ListNode) error {
 
 	switch r := p.next(); {
 	case r == eof || isEndOfLine(r):
-		return fmt.Errorf("unclosed action")
+		return p.errorf(p.pos-p.width, "unclosed action")
 	case r == ' ':
 		p.consumeText()
 	case r == '@' || r == '$': //the current object, just pass it
 		p.consumeText()
 	case r == '[':
 		return p.parseArray(cur)
+	case r == '|':
+		return p.parseStage(cur)
 	case r == '"' || r == '\'':
 		return p.parseQuote(cur, r)
 	case r == '.':
@@ -180,14 +304,14 @@ func (p *Parser) parseInsideAction(cur *ListNode) error {
 		p.backup()
 		return p.parseIdentifier(cur)
 	default:
-		return fmt.Errorf("unrecognized character in action: %#U", r)
+		return p.errorf(p.pos-p.width, "unrecognized character in action: %#U", r)
 	}
 	return p.parseInsideAction(cur)
 }
 
 // parseRightDelim scans the right delimiter, which is known to be present.
 func (p *Parser) parseRightDelim(_ *ListNode) error {
-	p.pos += len(rightDelim)
+	p.pos += len(p.rightDelim)
 	p.consumeText()
 	cur := p.Root
 	return p.parseText(cur)
@@ -195,8 +319,9 @@ func (p *Parser) parseRightDelim(_ *ListNode) error {
 
 // parseIdentifier scans build-in keywords, like "range" "end"
 func (p *Parser) parseIdentifier(cur *ListNode) error {
+	start := p.start
 	var r rune
-	for {
+	for !strings.HasPrefix(p.input[p.pos:], p.rightDelim) {
 		r = p.next()
 		if isTerminator(r) {
 			p.backup()
@@ -205,10 +330,14 @@ func (p *Parser) parseIdentifier(cur *ListNode) error {
 	}
 	value := p.consumeText()
 
+	if p.peek() == '(' {
+		return p.parseFunction(cur, value, false)
+	}
+
 	if isBool(value) {
 		v, err := strconv.ParseBool(value)
 		if err != nil {
-			return fmt.Errorf("can not parse bool '%s': %s", value, err.Error())
+			return p.errorf(start, "can not parse bool '%s': %s", value, err.Error())
 		}
 
 		cur.append(newBool(v))
@@ -232,6 +361,7 @@ func (p *Parser) parseRecursive(cur *ListNode) error {
 
 // parseNumber scans number
 func (p *Parser) parseNumber(cur *ListNode) error {
+	start := p.start
 	r := p.peek()
 	if r == '+' || r == '-' {
 		//lint:ignore SA4006 not harmful, leave as-is to make fork sync easier
@@ -255,16 +385,17 @@ func (p *Parser) parseNumber(cur *ListNode) error {
 		cur.append(newFloat(d))
 		return p.parseInsideAction(cur)
 	}
-	return fmt.Errorf("cannot parse number %s", value)
+	return p.errorf(start, "cannot parse number %s", value)
 }
 
 // parseArray scans array index selection
 func (p *Parser) parseArray(cur *ListNode) error {
+	start := p.start
 Loop:
 	for {
 		switch p.next() {
 		case eof, '\n':
-			return fmt.Errorf("unterminated array")
+			return p.errorf(start, "unterminated array")
 		case ']':
 			break Loop
 		}
@@ -279,12 +410,16 @@ Loop:
 	strs := strings.Split(text, ",")
 	if len(strs) > 1 {
 		var union []*ListNode
+		offset := start + 1
 		for _, str := range strs {
-			parser, err := parseAction("union", fmt.Sprintf("[%s]", strings.Trim(str, " ")))
+			trimmed := strings.Trim(str, " ")
+			parser, err := parseAction("union", fmt.Sprintf("[%s]", trimmed))
 			if err != nil {
-				return err
+				// the nested action starts with the added '['
+				return p.rebase(err, offset+strings.Index(str, trimmed)-1)
 			}
 			union = append(union, parser.Root)
+			offset += len(str) + 1
 		}
 		cur.append(newUnion(union))
 		return p.parseInsideAction(cur)
@@ -295,7 +430,7 @@ Loop:
 	if value != nil {
 		parser, err := parseAction("arraydict", fmt.Sprintf(".%s", value[1]))
 		if err != nil {
-			return err
+			return p.rebase(err, start+1)
 		}
 		for _, node := range parser.Root.Nodes {
 			cur.append(node)
@@ -306,7 +441,7 @@ Loop:
 	//slice operator
 	value = sliceOperatorRex.FindStringSubmatch(text)
 	if value == nil {
-		return fmt.Errorf("invalid array index %s", text)
+		return p.errorf(start+1, "invalid array index %s", text)
 	}
 	value = value[1:]
 	params := [3]ParamsEntry{}
@@ -322,7 +457,7 @@ Loop:
 				params[i].Known = true
 				params[i].Value, err = strconv.Atoi(value[i])
 				if err != nil {
-					return fmt.Errorf("array index %s is not a number", value[i])
+					return p.errorf(start+1, "array index %s is not a number", value[i])
 				}
 			}
 		} else {
@@ -336,65 +471,70 @@ Loop:
 			}
 		}
 	}
+	if params[2].Known && params[2].Value == 0 {
+		return p.errorf(start+1, "array slice step can't be 0")
+	}
 	cur.append(newArray(params))
 	return p.parseInsideAction(cur)
 }
 
 // parseFilter scans filter inside array selection
 func (p *Parser) parseFilter(cur *ListNode) error {
+	start := p.pos
 	p.pos += len("[?(")
 	p.consumeText()
-	begin := false
-	end := false
-	var pair rune
+	depth := 0
+	var quote rune
 
 Loop:
 	for {
 		r := p.next()
 		switch r {
 		case eof, '\n':
-			return fmt.Errorf("unterminated filter")
+			return p.errorf(start, "unterminated filter")
 		case '"', '\'':
-			//lint:ignore S1002 not harmful, leave as-is to make fork sync easier
-			if begin == false {
-				//save the paired rune
-				begin = true
-				pair = r
-				continue
+			//parentheses inside of a quoted string are ignored
+			if quote == 0 {
+				quote = r
+			} else if p.input[p.pos-2] != '\\' && r == quote {
+				quote = 0
 			}
-			//only add when met paired rune
-			if p.input[p.pos-2] != '\\' && r == pair {
-				end = true
+		case '(':
+			if quote == 0 {
+				depth++
 			}
 		case ')':
-			//in rightParser below quotes only appear zero or once
-			//and must be paired at the beginning and end
-			if begin == end {
-				break Loop
+			//function calls may nest parentheses inside of the filter
+			if quote == 0 {
+				if depth == 0 {
+					break Loop
+				}
+				depth--
 			}
 		}
 	}
 	if p.next() != ']' {
-		return fmt.Errorf("unclosed array expect ]")
+		return p.errorf(p.pos-p.width, "unclosed array expect ]")
 	}
 	reg := regexp.MustCompile(`^([^!<>=]+)([!<>=]+)(.+?)$`)
 	text := p.consumeText()
 	text = text[:len(text)-2]
 	value := reg.FindStringSubmatch(text)
+	textStart := start + len("[?(")
 	if value == nil {
 		parser, err := parseAction("text", text)
 		if err != nil {
-			return err
+			return p.rebase(err, textStart)
 		}
 		cur.append(newFilter(parser.Root, newList(), "exists"))
 	} else {
 		leftParser, err := parseAction("left", value[1])
 		if err != nil {
-			return err
+			return p.rebase(err, textStart)
 		}
 		rightParser, err := parseAction("right", value[3])
 		if err != nil {
-			return err
+			return p.rebase(err, textStart+len(value[1])+len(value[2]))
 		}
 		cur.append(newFilter(leftParser.Root, rightParser.Root, value[2]))
 	}
@@ -403,11 +543,12 @@ Loop:
 
 // parseQuote unquotes string inside double or single quote
 func (p *Parser) parseQuote(cur *ListNode, end rune) error {
+	start := p.start
 Loop:
 	for {
 		switch p.next() {
 		case eof, '\n':
-			return fmt.Errorf("unterminated quoted string")
+			return p.errorf(start, "unterminated quoted string")
 		case end:
 			//if it's not escape break the Loop
 			if p.input[p.pos-2] != '\\' {
@@ -418,7 +559,7 @@ Loop:
 	value := p.consumeText()
 	s, err := UnquoteExtend(value)
 	if err != nil {
-		return fmt.Errorf("unquote string %s error %v", value, err)
+		return p.errorf(start, "unquote string %s error %v", value, err)
 	}
 	cur.append(newText(s))
 	return p.parseInsideAction(cur)
@@ -432,14 +573,155 @@ func (p *Parser) parseField(cur *ListNode) error {
 	value := p.consumeText()
 	if value == "*" {
 		cur.append(newWildcard())
+	} else if p.peek() == '(' {
+		return p.parseFunction(cur, value, true)
 	} else {
 		cur.append(newField(strings.Replace(value, "\\", "", -1)))
 	}
 	return p.parseInsideAction(cur)
 }
 
+// parseFunction scans the arguments of a function call, the opening parenthesis is known to be present
+func (p *Parser) parseFunction(cur *ListNode, name string, method bool) error {
+	args, err := p.parseArguments("function call " + name)
+	if err != nil {
+		return err
+	}
+	cur.append(newFunction(name, args, method))
+	return p.parseInsideAction(cur)
+}
+
+// parseStage scans a pipeline stage with its optional arguments, the pipe is known to be present
+func (p *Parser) parseStage(cur *ListNode) error {
+	p.consumeText()
+	for isSpace(p.peek()) {
+		p.next()
+	}
+	p.consumeText()
+	start := p.pos
+	for isAlphaNumeric(p.peek()) {
+		p.next()
+	}
+	name := p.consumeText()
+	if name == "" {
+		return p.errorf(start, "missing stage name after |")
+	}
+	s, ok := stages[name]
+	if !ok {
+		return p.errorf(start, "unknown stage %s", name)
+	}
+	var args []*ListNode
+	if p.peek() == '(' {
+		var err error
+		if args, err = p.parseArguments("stage " + name); err != nil {
+			return err
+		}
+	}
+	if len(args) < s.minArgs || (s.maxArgs >= 0 && len(args) > s.maxArgs) {
+		return p.errorf(start, "wrong number of arguments for stage %s: %d", name, len(args))
+	}
+	cur.append(newStage(name, args))
+	return p.parseInsideAction(cur)
+}
+
+// parseArguments scans comma separated arguments in parentheses, the opening parenthesis is known to be present
+func (p *Parser) parseArguments(call string) ([]*ListNode, error) {
+	start := p.pos
+	p.next()
+	p.consumeText()
+	depth := 0
+	var quote rune
+
+Loop:
+	for {
+		r := p.next()
+		switch r {
+		case eof, '\n':
+			return nil, p.errorf(start, "unterminated %s", call)
+		case '"', '\'':
+			if quote == 0 {
+				quote = r
+			} else if p.input[p.pos-2] != '\\' && r == quote {
+				quote = 0
+			}
+		case '(':
+			if quote == 0 {
+				depth++
+			}
+		case ')':
+			if quote == 0 {
+				if depth == 0 {
+					break Loop
+				}
+				depth--
+			}
+		}
+	}
+	text := p.consumeText()
+	text = text[:len(text)-1]
+
+	var args []*ListNode
+	texts, offsets := splitArguments(text)
+	for i, arg := range texts {
+		if arg == "" {
+			return nil, p.errorf(start+1+offsets[i], "empty argument in %s", call)
+		}
+		parser, err := parseAction("argument", arg)
+		if err != nil {
+			return nil, p.rebase(err, start+1+offsets[i])
+		}
+		args = append(args, parser.Root)
+	}
+	return args, nil
+}
+
+// splitArguments splits function call arguments on commas outside of quotes and parentheses,
+// it returns the trimmed arguments and their byte offsets in the text
+func splitArguments(text string) (args []string, offsets []int) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	appendArgument := func(start, end int) {
+		arg := text[start:end]
+		trimmed := strings.TrimSpace(arg)
+		args = append(args, trimmed)
+		offsets = append(offsets, start+len(arg)-len(strings.TrimLeft(arg, " \t")))
+	}
+	depth := 0
+	start := 0
+	var quote rune
+	for i, r := range text {
+		switch r {
+		case '"', '\'':
+			if quote == 0 {
+				quote = r
+			} else if r == quote && (i == 0 || text[i-1] != '\\') {
+				quote = 0
+			}
+		case '(', '[':
+			if quote == 0 {
+				depth++
+			}
+		case ')', ']':
+			if quote == 0 {
+				depth--
+			}
+		case ',':
+			if quote == 0 && depth == 0 {
+				appendArgument(start, i)
+				start = i + 1
+			}
+		}
+	}
+	appendArgument(start, len(text))
+	return args, offsets
+}
+
 // advance scans until next non-escaped terminator
 func (p *Parser) advance() bool {
+	if strings.HasPrefix(p.input[p.pos:], p.rightDelim) {
+		return false
+	}
 	r := p.next()
 	if r == '\\' {
 		p.next()
@@ -456,7 +738,7 @@ func isTerminator(r rune) bool {
 		return true
 	}
 	switch r {
-	case eof, '.', ',', '[', ']', '$', '@', '{', '}':
+	case eof, '.', ',', '[', ']', '$', '@', '{', '}', '(', ')', '|':
 		return true
 	}
 	return false
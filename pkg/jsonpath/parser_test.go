@@ -77,6 +77,14 @@ var parserTests = []parserTest{
 		[]Node{newList(), newFilter(newList(), newList(), "=="), newList(), newField("status"), newField("nodeInfo"), newField("osImage"), newList(), newText("\"\"")}, false},
 	{"single containing escaped single", `{[?(@.status.nodeInfo.osImage == '\\\'')]}`,
 		[]Node{newList(), newFilter(newList(), newList(), "=="), newList(), newField("status"), newField("nodeInfo"), newField("osImage"), newList(), newText("\\'")}, false},
+	{"function", `{length(@.items)}`,
+		[]Node{newList(), newFunction("length", nil, false), newList(), newField("items")}, false},
+	{"function step", `{.name.startsWith('a')}`,
+		[]Node{newList(), newField("name"), newFunction("startsWith", nil, true), newList(), newText("a")}, false},
+	{"function filter", `{[?(contains(@.tags, "a,b") == true)]}`,
+		[]Node{newList(), newFilter(newList(), newList(), "=="),
+			newList(), newFunction("contains", nil, false), newList(), newField("tags"), newList(), newText("a,b"),
+			newList(), newBool(true)}, false},
 	{"negative index slice, equals a[len-5] to a[len-1]", `{[-5:]}`, []Node{newList(),
 		newArray([3]ParamsEntry{{-5, true, false}, {0, false, false}, {0, false, false}})}, false},
 	{"negative index slice, equals a[len-1]", `{[-1]}`, []Node{newList(),
@@ -99,6 +107,10 @@ func collectNode(nodes []Node, cur Node) []Node {
 		for _, node := range cur.(*UnionNode).Nodes {
 			nodes = collectNode(nodes, node)
 		}
+	case NodeFunction:
+		for _, node := range cur.(*FunctionNode).Args {
+			nodes = collectNode(nodes, node)
+		}
 	}
 	return nodes
 }
@@ -136,11 +148,14 @@ type failParserTest struct {
 
 func TestFailParser(t *testing.T) {
 	failParserTests := []failParserTest{
-		{"unclosed action", "{.hello", "unclosed action"},
-		{"unrecognized character", "{*}", "unrecognized character in action: U+002A '*'"},
-		{"invalid number", "{+12.3.0}", "cannot parse number +12.3.0"},
-		{"unterminated array", "{[1}", "unterminated array"},
-		{"unterminated filter", "{[?(.price]}", "unterminated filter"},
+		{"unclosed action", "{.hello", "unclosed action at line 1, column 8"},
+		{"unrecognized character", "{*}", "unrecognized character in action: U+002A '*' at line 1, column 2"},
+		{"invalid number", "{+12.3.0}", "cannot parse number +12.3.0 at line 1, column 2"},
+		{"unterminated array", "{[1}", "unterminated array at line 1, column 2"},
+		{"unterminated filter", "{[?(.price]}", "unterminated filter at line 1, column 2"},
+		{"unterminated function", "{length(.items}", "unterminated function call length at line 1, column 8"},
+		{"empty argument", "{contains(.items,)}", "empty argument in function call contains at line 1, column 18"},
+		{"slice step 0", "{.items[1:5:0]}", "array slice step can't be 0 at line 1, column 9"},
 	}
 	for _, test := range failParserTests {
 		_, err := Parse(test.name, test.text)
@@ -155,3 +170,96 @@ func TestFailParser(t *testing.T) {
 		}
 	}
 }
+
+type parseErrorTest struct {
+	name    string
+	text    string
+	offset  int
+	line    int
+	column  int
+	snippet string
+}
+
+func TestParseError(t *testing.T) {
+	parseErrorTests := []parseErrorTest{
+		{"unclosed action", "{.hello", 7, 1, 8, "{.hello\n       ^"},
+		{"unrecognized character", "hello {.a} {*}", 12, 1, 13, "hello {.a} {*}\n            ^"},
+		{"multiline", "hello\n\t{.a}{$.b[?(@.x == 1]}", 15, 2, 10, "\t{.a}{$.b[?(@.x == 1]}\n\t        ^"},
+		{"unicode", "żółć {'zażółć}", 10, 1, 7, "żółć {'zażółć}\n      ^"},
+		{"nested union", "{.a['b', x y]}", 9, 1, 10, "{.a['b', x y]}\n         ^"},
+		{"nested filter", "{.a[?(@.b == *)]}", 13, 1, 14, "{.a[?(@.b == *)]}\n             ^"},
+		{"nested argument", "{length(@.a, *)}", 13, 1, 14, "{length(@.a, *)}\n             ^"},
+	}
+	for _, test := range parseErrorTests {
+		_, err := Parse(test.name, test.text)
+		e, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("in %s, expect to get *ParseError, got %#v", test.name, err)
+			continue
+		}
+		if e.Expression != test.text {
+			t.Errorf("in %s, expect expression %q, got %q", test.name, test.text, e.Expression)
+		}
+		if e.Offset != test.offset || e.Line != test.line || e.Column != test.column {
+			t.Errorf("in %s, expect offset %d at %d:%d, got offset %d at %d:%d",
+				test.name, test.offset, test.line, test.column, e.Offset, e.Line, e.Column)
+		}
+		if e.Snippet() != test.snippet {
+			t.Errorf("in %s, expect snippet\n%s\ngot\n%s", test.name, test.snippet, e.Snippet())
+		}
+	}
+}
+
+func TestParserOptions(t *testing.T) {
+	parserOptionsTests := []struct {
+		parserTest
+		options []func(*Parser)
+	}{
+		{parserTest{"delims", `hello {{.jsonpath}} {x}`,
+			[]Node{newText("hello "), newList(), newField("jsonpath"), newText(" {x}")}, false},
+			[]func(*Parser){WithDelim("{{", "}}")}},
+		{parserTest{"delims with filter", `<%.items[?(@.name=="a")].value%>`,
+			[]Node{newList(), newField("items"), newFilter(newList(), newList(), "=="),
+				newList(), newField("name"), newList(), newText("a"), newField("value")}, false},
+			[]func(*Parser){WithDelim("<%", "%>")}},
+		{parserTest{"delims with identifier", `<<range .items>><<.name>><<end>>`,
+			[]Node{newList(), newIdentifier("range"), newField("items"),
+				newList(), newField("name"), newList(), newIdentifier("end")}, false},
+			[]func(*Parser){WithDelim("<<", ">>")}},
+		{parserTest{"escape", `\{"a": {.a}, "b": \\\}`,
+			[]Node{newText(`{"a": `), newList(), newField("a"), newText(`, "b": \}`)}, false},
+			[]func(*Parser){WithEscape(`\`)}},
+		{parserTest{"escape unknown", `\x {.a}`,
+			[]Node{newText(`\x `), newList(), newField("a")}, false},
+			[]func(*Parser){WithEscape(`\`)}},
+		{parserTest{"escape with delims", `$$$$$$$${{.a}}`,
+			[]Node{newText(`$$$$`), newList(), newField("a")}, false},
+			[]func(*Parser){WithDelim("{{", "}}"), WithEscape(`$$`)}},
+		{parserTest{"empty delims", `{.a}`, nil, true},
+			[]func(*Parser){WithDelim("", "}")}},
+	}
+	for _, test := range parserOptionsTests {
+		parser, err := Parse(test.name, test.text, test.options...)
+		if test.shouldError {
+			if err == nil {
+				t.Errorf("unexpected non-error when parsing %s", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parse %s error %v", test.name, err)
+			continue
+		}
+		result := collectNode([]Node{}, parser.Root)[1:]
+		if len(result) != len(test.nodes) {
+			t.Errorf("in %s, expect to get %d nodes, got %d nodes", test.name, len(test.nodes), len(result))
+			t.Error(result)
+			continue
+		}
+		for i, expect := range test.nodes {
+			if result[i].String() != expect.String() {
+				t.Errorf("in %s, %dth node, expect %v, got %v", test.name, i, expect, result[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package jsonpath
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/VirtusLab/go-extended/pkg/jsonpath/template"
+)
+
+// stage transforms the whole result set of the preceding steps
+type stage struct {
+	minArgs, maxArgs int
+	fn               func(j *JSONPath, input []reflect.Value, args []*ListNode) ([]reflect.Value, error)
+}
+
+// stages holds the pipeline stages, e.g. {range .items[*] | sortBy(.name) | limit(10)}
+var stages map[string]stage
+
+func init() {
+	stages = map[string]stage{
+		"sortBy":  {1, -1, sortBy},
+		"unique":  {0, 1, unique},
+		"limit":   {1, 1, limit},
+		"reverse": {0, 0, reverse},
+		"groupBy": {1, 1, groupBy},
+	}
+}
+
+// evalStage evaluates StageNode
+func (j *JSONPath) evalStage(input []reflect.Value, node *StageNode) ([]reflect.Value, error) {
+	s, ok := stages[node.Name]
+	if !ok {
+		return input, fmt.Errorf("unknown stage %s", node.Name)
+	}
+	if len(node.Args) < s.minArgs || (s.maxArgs >= 0 && len(node.Args) > s.maxArgs) {
+		return input, fmt.Errorf("wrong number of arguments for stage %s: %d", node.Name, len(node.Args))
+	}
+	return s.fn(j, input, node.Args)
+}
+
+// sortBy sorts the values by the keys, the later keys are compared when the former are equal
+func sortBy(j *JSONPath, input []reflect.Value, args []*ListNode) ([]reflect.Value, error) {
+	keys := make([][]interface{}, len(input))
+	for i, value := range input {
+		keys[i] = make([]interface{}, len(args))
+		for k, arg := range args {
+			key, err := j.stageKey(value, arg)
+			if err != nil {
+				return input, err
+			}
+			keys[i][k] = key
+		}
+	}
+	indexes := make([]int, len(input))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		for k := range args {
+			if c := compareValues(keys[indexes[a]][k], keys[indexes[b]][k]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	results := make([]reflect.Value, len(input))
+	for i, index := range indexes {
+		results[i] = input[index]
+	}
+	return results, nil
+}
+
+// unique removes the repeated values, or the values with a repeated key, the first one is kept
+func unique(j *JSONPath, input []reflect.Value, args []*ListNode) ([]reflect.Value, error) {
+	var results []reflect.Value
+	var seen []interface{}
+	for _, value := range input {
+		key := value.Interface()
+		if len(args) > 0 {
+			var err error
+			if key, err = j.stageKey(value, args[0]); err != nil {
+				return input, err
+			}
+		}
+		if containsValue(seen, key) {
+			continue
+		}
+		seen = append(seen, key)
+		results = append(results, value)
+	}
+	return results, nil
+}
+
+// limit keeps the given number of the first values
+func limit(j *JSONPath, input []reflect.Value, args []*ListNode) ([]reflect.Value, error) {
+	values, err := j.evalList(j.cur, args[0])
+	if err != nil {
+		return input, err
+	}
+	if len(values) != 1 {
+		return input, fmt.Errorf("limit must be a single number")
+	}
+	n, ok := toInt(values[0].Interface())
+	if !ok || n < 0 {
+		return input, fmt.Errorf("limit %v is not a non-negative integer", values[0])
+	}
+	if n < len(input) {
+		return input[:n], nil
+	}
+	return input, nil
+}
+
+// reverse reverses the order of the values
+func reverse(_ *JSONPath, input []reflect.Value, _ []*ListNode) ([]reflect.Value, error) {
+	results := make([]reflect.Value, len(input))
+	for i, value := range input {
+		results[len(input)-1-i] = value
+	}
+	return results, nil
+}
+
+// groupBy collects the values with an equal key into groups like {"key": "Pod", "items": [...]},
+// in order of the first appearance of the key
+func groupBy(j *JSONPath, input []reflect.Value, args []*ListNode) ([]reflect.Value, error) {
+	var keys []interface{}
+	var groups [][]interface{}
+	for _, value := range input {
+		key, err := j.stageKey(value, args[0])
+		if err != nil {
+			return input, err
+		}
+		i := indexOfValue(keys, key)
+		if i < 0 {
+			i = len(keys)
+			keys = append(keys, key)
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], value.Interface())
+	}
+	results := make([]reflect.Value, len(keys))
+	for i := range keys {
+		results[i] = reflect.ValueOf(map[string]interface{}{
+			"key":   keys[i],
+			"items": groups[i],
+		})
+	}
+	return results, nil
+}
+
+// stageKey evaluates the argument against the value, a missing key is nil and many values are a slice
+func (j *JSONPath) stageKey(value reflect.Value, arg *ListNode) (interface{}, error) {
+	memory := *j
+	memory.allowMissingKeys = true
+	values, err := memory.evalList([]reflect.Value{value}, arg)
+	if err != nil {
+		return nil, err
+	}
+	switch len(values) {
+	case 0:
+		return nil, nil
+	case 1:
+		return values[0].Interface(), nil
+	}
+	all := make([]interface{}, len(values))
+	for i := range values {
+		all[i] = values[i].Interface()
+	}
+	return all, nil
+}
+
+// compareValues orders nil first, then bools, numbers, strings and other values compared as text
+func compareValues(a, b interface{}) int {
+	ra, rb := valueRank(a), valueRank(b)
+	if ra != rb {
+		return ra - rb
+	}
+	va, _ := template.Indirect(reflect.ValueOf(a))
+	vb, _ := template.Indirect(reflect.ValueOf(b))
+	switch ra {
+	case 0:
+		return 0
+	case 1:
+		return boolRank(va.Bool()) - boolRank(vb.Bool())
+	case 2:
+		floatType := reflect.TypeOf(float64(0))
+		fa, fb := va.Convert(floatType).Float(), vb.Convert(floatType).Float()
+		return compareOrdered(fa < fb, fa > fb)
+	case 3:
+		return compareOrdered(va.String() < vb.String(), va.String() > vb.String())
+	}
+	sa, sb := fmt.Sprint(a), fmt.Sprint(b)
+	return compareOrdered(sa < sb, sa > sb)
+}
+
+func valueRank(v interface{}) int {
+	value, isNil := template.Indirect(reflect.ValueOf(v))
+	switch {
+	case !value.IsValid() || isNil:
+		return 0
+	case value.Kind() == reflect.Bool:
+		return 1
+	case isNumber(value.Kind()):
+		return 2
+	case value.Kind() == reflect.String:
+		return 3
+	}
+	return 4
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// indexOfValue returns the index of the first value equal to v, or -1
+func indexOfValue(values []interface{}, v interface{}) int {
+	for i := range values {
+		if isEqual(reflect.ValueOf(values[i]), reflect.ValueOf(v)) || reflect.DeepEqual(values[i], v) {
+			return i
+		}
+	}
+	return -1
+}
+
+// containsValue reports whether a value is equal to v
+func containsValue(values []interface{}, v interface{}) bool {
+	return indexOfValue(values, v) >= 0
+}
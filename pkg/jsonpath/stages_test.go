@@ -0,0 +1,58 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStages(t *testing.T) {
+	var input = []byte(`{
+		"limit": 2,
+		"items": [
+			{"kind": "Pod", "name": "web", "priority": 2},
+			{"kind": "Service", "name": "db", "priority": 1},
+			{"kind": "Pod", "name": "cache"},
+			{"kind": "Pod", "name": "db", "priority": 1.5}
+		]
+	}`)
+	var data interface{}
+	err := json.Unmarshal(input, &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stageTests := []jsonpathTest{
+		{"sortBy", "{.items[*] | sortBy(.name) | limit(10)}", data,
+			`map[kind:Pod name:cache] map[kind:Service name:db priority:1] map[kind:Pod name:db priority:1.5] map[kind:Pod name:web priority:2]`, false},
+		{"sortBy in range", `{range .items[*] | sortBy(.name)}{.name} {end}`, data, "cache db db web ", false},
+		{"sortBy many keys", `{range .items[*] | sortBy(.name, .kind)}{.kind} {end}`, data, "Pod Pod Service Pod ", false},
+		{"sortBy number", `{range .items[*] | sortBy(@.priority)}{.name} {end}`, data, "cache db db web ", false},
+		{"sortBy values", `{.items[*].name | sortBy(@)}`, data, "cache db db web", false},
+		{"unique", `{.items[*].name | unique}`, data, "web db cache", false},
+		{"unique by key", `{range .items[*] | unique(.kind)}{.name} {end}`, data, "web db ", false},
+		{"limit", `{.items[*].name | limit(2)}`, data, "web db", false},
+		{"limit from data", `{.items[*].name | limit(@.limit)}`, data, "web db", false},
+		{"limit zero", `{.items[*].name | limit(0)}`, data, "", false},
+		{"reverse", `{.items[*].name | reverse}`, data, "db cache db web", false},
+		{"pipeline", `{.items[*].name | unique | sortBy(@) | reverse | limit(2)}`, data, "web db", false},
+		{"groupBy", `{range .items[*] | groupBy(.kind)}{.key}: {.items[*].name}{"\n"}{end}`, data,
+			"Pod: web cache db\nService: db\n", false},
+		{"groupBy count", `{range .items[*] | groupBy(.kind)}{.key}={.items.length()} {end}`, data, "Pod=3 Service=1 ", false},
+		{"negative limit", `{.items[*].name | limit(-1)}`, data, "", true},
+	}
+	testJSONPath(stageTests, false, t)
+
+	failStageTests := []failParserTest{
+		{"unknown stage", "{.items | sort}", "unknown stage sort at line 1, column 11"},
+		{"missing stage", "{.items | }", "missing stage name after | at line 1, column 11"},
+		{"missing arguments", "{.items | limit}", "wrong number of arguments for stage limit: 0 at line 1, column 11"},
+		{"too many arguments", "{.items | reverse(1)}", "wrong number of arguments for stage reverse: 1 at line 1, column 11"},
+		{"unterminated arguments", "{.items | sortBy(.name}", "unterminated stage sortBy at line 1, column 17"},
+	}
+	for _, test := range failStageTests {
+		_, err := Parse(test.name, test.text)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("in %s, expect to get error %q, got %v", test.name, test.err, err)
+		}
+	}
+}
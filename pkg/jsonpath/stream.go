@@ -0,0 +1,255 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+// Stream evaluates the expression against the JSON values read from the reader and calls fn for every match,
+// without loading the whole input into memory. Returning an error from fn stops the evaluation and the error is returned.
+//
+// The expression must be a single path, e.g. {.items[*].metadata.name}. The path steps are matched
+// while the input is being tokenized as long as they are:
+//   - child fields, e.g. .metadata or ['metadata']
+//   - wildcards, e.g. .* or [*]
+//   - non-negative indexes and slices, e.g. [0], [2:10] or [::2]
+//   - filters on array elements, e.g. [?(@.kind == "Pod")], where each element is buffered to be tested
+//
+// The first step outside of this subset, and all the following steps, are evaluated in memory on the buffered value
+// it applies to. Recursive descent (..), negative indexes, unions and functions need the whole value buffered,
+// e.g. {.items[-1]} buffers the items array and {..name} buffers each document.
+// Missing keys and out of range indexes are never an error while streaming, they just do not match.
+func (j *JSONPath) Stream(r io.Reader, fn func(value interface{}) error) error {
+	steps, err := j.path()
+	if err != nil {
+		return err
+	}
+
+	// the buffered values are evaluated on a copy, with missing keys allowed, the same as the streamed ones
+	memory := *j
+	memory.allowMissingKeys = true
+
+	decoder := json.NewDecoder(r)
+	for decoder.More() {
+		if err := memory.stream(decoder, steps, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// path parses the expression and returns the steps of the single path it holds
+func (j *JSONPath) path() ([]Node, error) {
+	if err := j.Parse(); err != nil {
+		return nil, err
+	}
+	nodes := j.parser.Root.Nodes
+	if len(nodes) != 1 || nodes[0].Type() != NodeList {
+		return nil, fmt.Errorf("%s is not a single path expression", j.expr)
+	}
+	steps := nodes[0].(*ListNode).Nodes
+	for _, step := range steps {
+		if step.Type() == NodeIdentifier || step.Type() == NodeStage {
+			return nil, fmt.Errorf("%s is not a path step", step)
+		}
+	}
+	return steps, nil
+}
+
+// isStreamable reports whether the step can be matched while tokenizing the input
+func isStreamable(step Node) bool {
+	switch step := step.(type) {
+	case *FieldNode, *WildcardNode, *FilterNode:
+		return true
+	case *ArrayNode:
+		_, _, _, ok := streamRange(step.Params)
+		return ok
+	}
+	return false
+}
+
+// streamRange returns the start, end and step of an index or a slice, if it does not depend on the array length
+func streamRange(params [3]ParamsEntry) (start, end, step int, ok bool) {
+	start, end, step = 0, math.MaxInt32, 1
+	if params[0].Known {
+		start = params[0].Value
+	}
+	if params[1].Known {
+		end = params[1].Value
+	}
+	if params[2].Known {
+		step = params[2].Value
+	}
+	return start, end, step, start >= 0 && end >= 0 && step > 0
+}
+
+// stream matches the value at the decoder position against the steps and calls fn for every match
+func (j *JSONPath) stream(decoder *json.Decoder, steps []Node, fn func(value interface{}) error) error {
+	if len(steps) == 0 || !isStreamable(steps[0]) {
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		return j.evalBuffered(value, steps, fn)
+	}
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	delim, _ := token.(json.Delim)
+	switch step := steps[0].(type) {
+	case *FieldNode:
+		if delim == '{' {
+			return j.streamObject(decoder, func(key string) []Node {
+				if key == step.Value {
+					return steps[1:]
+				}
+				return nil
+			}, fn)
+		}
+	case *WildcardNode:
+		if delim == '{' {
+			return j.streamObject(decoder, func(string) []Node {
+				return steps[1:]
+			}, fn)
+		}
+		if delim == '[' {
+			return j.streamArray(decoder, func(int) []Node {
+				return steps[1:]
+			}, fn)
+		}
+	case *ArrayNode:
+		if delim == '[' {
+			start, end, by, _ := streamRange(step.Params)
+			return j.streamArray(decoder, func(i int) []Node {
+				if i >= start && i < end && (i-start)%by == 0 {
+					return steps[1:]
+				}
+				return nil
+			}, fn)
+		}
+	case *FilterNode:
+		if delim == '[' {
+			return j.streamFiltered(decoder, step, steps[1:], fn)
+		}
+	}
+	return skip(decoder, delim)
+}
+
+// streamObject streams the values of the object for the keys with steps and skips the others,
+// the opening delimiter is known to be consumed
+func (j *JSONPath) streamObject(decoder *json.Decoder, stepsFor func(key string) []Node, fn func(value interface{}) error) error {
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		steps := stepsFor(token.(string))
+		if steps == nil {
+			err = skipValue(decoder)
+		} else {
+			err = j.stream(decoder, steps, fn)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := decoder.Token()
+	return err
+}
+
+// streamArray streams the elements of the array for the indexes with steps and skips the others,
+// the opening delimiter is known to be consumed
+func (j *JSONPath) streamArray(decoder *json.Decoder, stepsFor func(index int) []Node, fn func(value interface{}) error) error {
+	for i := 0; decoder.More(); i++ {
+		var err error
+		steps := stepsFor(i)
+		if steps == nil {
+			err = skipValue(decoder)
+		} else {
+			err = j.stream(decoder, steps, fn)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := decoder.Token()
+	return err
+}
+
+// streamFiltered buffers every element of the array to test the filter, and evaluates the steps on the matching ones,
+// the opening delimiter is known to be consumed
+func (j *JSONPath) streamFiltered(decoder *json.Decoder, filter *FilterNode, steps []Node, fn func(value interface{}) error) error {
+	for decoder.More() {
+		var element interface{}
+		if err := decoder.Decode(&element); err != nil {
+			return err
+		}
+		matches, err := j.evalFilter([]reflect.Value{reflect.ValueOf([]interface{}{element})}, filter)
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		if err := j.evalBuffered(element, steps, fn); err != nil {
+			return err
+		}
+	}
+	_, err := decoder.Token()
+	return err
+}
+
+// evalBuffered evaluates the steps in memory and calls fn for every match
+func (j *JSONPath) evalBuffered(value interface{}, steps []Node, fn func(value interface{}) error) error {
+	list := newList()
+	list.Nodes = steps
+	results, err := j.evalList([]reflect.Value{reflect.ValueOf(value)}, list)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		iface, err := j.EvalToInterface(result)
+		if err != nil {
+			return err
+		}
+		if err := fn(iface); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipValue skips the value at the decoder position
+func skipValue(decoder *json.Decoder) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	delim, _ := token.(json.Delim)
+	return skip(decoder, delim)
+}
+
+// skip skips the rest of an object or an array, if the delimiter opens one
+func skip(decoder *json.Decoder, delim json.Delim) error {
+	if delim != '{' && delim != '[' {
+		return nil
+	}
+	for depth := 1; depth > 0; {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type streamTest struct {
+	name     string
+	template string
+	input    string
+	expect   []interface{}
+	err      string
+}
+
+func TestStream(t *testing.T) {
+	var input = `{
+		"kind": "List",
+		"items": [
+			{"kind": "Pod", "metadata": {"name": "pod1", "labels": {"app": "web"}}, "ports": [80, 443]},
+			{"kind": "Service", "metadata": {"name": "svc1", "labels": {}}, "ports": [80]},
+			{"kind": "Pod", "metadata": {"name": "pod2", "labels": {"app": "db"}}, "ports": []}
+		]
+	}`
+
+	streamTests := []streamTest{
+		{"field", "{.kind}", input, []interface{}{"List"}, ""},
+		{"path", "{.items[*].metadata.name}", input, []interface{}{"pod1", "svc1", "pod2"}, ""},
+		{"bracket field", "{['items'][0]['kind']}", input, []interface{}{"Pod"}, ""},
+		{"index", "{.items[1].metadata.name}", input, []interface{}{"svc1"}, ""},
+		{"slice", "{.items[0:3:2].metadata.name}", input, []interface{}{"pod1", "pod2"}, ""},
+		{"wildcard object", "{.items[0].metadata.labels.*}", input, []interface{}{"web"}, ""},
+		{"wildcard array", "{.items[*].ports.*}", input, []interface{}{80.0, 443.0, 80.0}, ""},
+		{"object", "{.items[1].metadata}", input,
+			[]interface{}{map[string]interface{}{"name": "svc1", "labels": map[string]interface{}{}}}, ""},
+		{"filter", `{.items[?(@.kind == "Pod")].metadata.name}`, input, []interface{}{"pod1", "pod2"}, ""},
+		{"filter exists", `{.items[?(@.metadata.labels.app)].metadata.name}`, input, []interface{}{"pod1", "pod2"}, ""},
+		{"buffered negative index", "{.items[-1].metadata.name}", input, []interface{}{"pod2"}, ""},
+		{"buffered recursive", "{.items[0]..name}", input, []interface{}{"pod1"}, ""},
+		{"buffered union", "{.items[*].metadata['name', 'labels']}", input, []interface{}{
+			"pod1", map[string]interface{}{"app": "web"},
+			"svc1", map[string]interface{}{},
+			"pod2", map[string]interface{}{"app": "db"},
+		}, ""},
+		{"buffered function", "{.items[*].ports.length()}", input, []interface{}{2, 1, 0}, ""},
+		{"missing key", "{.items[*].status.phase}", input, nil, ""},
+		{"out of range", "{.items[5]}", input, nil, ""},
+		{"not an object", "{.kind.name}", input, nil, ""},
+		{"concatenated", "{.name}", `{"name": "a"} {"name": "b"}` + "\n" + `{"id": 3}`, []interface{}{"a", "b"}, ""},
+		{"root", "{@}", `[1, 2]`, []interface{}{[]interface{}{1.0, 2.0}}, ""},
+		{"invalid json", "{.items[*].kind}", `{"items": [{"kind": }]}`, nil, "invalid character '}' looking for beginning of value"},
+		{"not a path", "{.kind} {.items}", input, nil, "{.kind} {.items} is not a single path expression"},
+		{"range", "{range .items[*]}{.kind}{end}", input, nil, "{range .items[*]}{.kind}{end} is not a single path expression"},
+	}
+	for _, test := range streamTests {
+		var out []interface{}
+		err := New(test.template).Stream(strings.NewReader(test.input), func(value interface{}) error {
+			out = append(out, value)
+			return nil
+		})
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("in %s, expect to get error %q, got %v", test.name, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("in %s, stream error %v", test.name, err)
+			continue
+		}
+		if fmt.Sprint(out) != fmt.Sprint(test.expect) {
+			t.Errorf("in %s, expect to get %v, got %v", test.name, test.expect, out)
+		}
+	}
+}
+
+func TestStreamStop(t *testing.T) {
+	stop := errors.New("stop")
+	var out []interface{}
+	err := New("{[*].id}").Stream(strings.NewReader(`[{"id": 1}, {"id": 2}, {"id": 3}]`), func(value interface{}) error {
+		out = append(out, value)
+		if len(out) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("expect to get error %v, got %v", stop, err)
+	}
+	if fmt.Sprint(out) != "[1 2]" {
+		t.Errorf("expect to get [1 2], got %v", out)
+	}
+}
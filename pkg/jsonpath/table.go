@@ -0,0 +1,306 @@
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// TableFormat identifies how a table is written
+type TableFormat int
+
+const (
+	// TableText writes the table as text aligned in columns, the default
+	TableText TableFormat = iota
+	// TableCSV writes the table as comma separated values with a header
+	TableCSV
+	// TableTSV writes the table as tab separated values with a header
+	TableTSV
+	// TableMarkdown writes the table as a Markdown table
+	TableMarkdown
+	// TableJSON writes the table as an array of JSON objects with the headers as keys
+	TableJSON
+)
+
+// TableFormatName maps table format code to table format text representation
+var TableFormatName = map[TableFormat]string{
+	TableText:     "text",
+	TableCSV:      "csv",
+	TableTSV:      "tsv",
+	TableMarkdown: "markdown",
+	TableJSON:     "json",
+}
+
+func (f TableFormat) String() string {
+	return TableFormatName[f]
+}
+
+// ParseTableFormat returns the table format with the given text representation
+func ParseTableFormat(name string) (TableFormat, error) {
+	for format, formatName := range TableFormatName {
+		if strings.EqualFold(name, formatName) {
+			return format, nil
+		}
+	}
+	return TableText, fmt.Errorf("unknown table format %s", name)
+}
+
+// Column is a table column with the expression evaluated for every row
+type Column struct {
+	Header     string
+	Expression string
+}
+
+// ParseColumns parses the column definitions, the same as kubectl custom-columns,
+// e.g. NAME:.metadata.name,AGE:.age; the braces around the expressions are optional
+func ParseColumns(spec string) ([]Column, error) {
+	definitions, _ := splitArguments(spec)
+	if len(definitions) == 0 {
+		return nil, fmt.Errorf("no column definitions")
+	}
+	columns := make([]Column, len(definitions))
+	for i, definition := range definitions {
+		parts := strings.SplitN(definition, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid column definition %q, expected HEADER:EXPRESSION", definition)
+		}
+		expression := parts[1]
+		if !strings.Contains(expression, leftDelim) {
+			expression = leftDelim + expression + rightDelim
+		}
+		columns[i] = Column{Header: parts[0], Expression: expression}
+	}
+	return columns, nil
+}
+
+// Table evaluates the columns for every document and writes the rows
+type Table struct {
+	columns          []Column
+	allowMissingKeys bool
+	placeholder      string
+	format           TableFormat
+}
+
+// cell is the evaluated column of a row, the values are nil when missing
+type cell struct {
+	text   string
+	values []interface{}
+}
+
+// NewTable creates a new table with the given columns, see ParseColumns
+func NewTable(columns ...Column) *Table {
+	return &Table{
+		columns:     columns,
+		placeholder: "<none>",
+	}
+}
+
+// AllowMissingKeys allows a caller to specify whether they want an error if a field or map key
+// cannot be located, or the placeholder written instead. The receiver is returned for chaining.
+func (t *Table) AllowMissingKeys(allow bool) *Table {
+	t.allowMissingKeys = allow
+	return t
+}
+
+// Placeholder sets the text written for the missing values, <none> by default.
+// The receiver is returned for chaining.
+func (t *Table) Placeholder(placeholder string) *Table {
+	t.placeholder = placeholder
+	return t
+}
+
+// Output sets the format used to write the table, see TableFormat.
+// The receiver is returned for chaining.
+func (t *Table) Output(format TableFormat) *Table {
+	t.format = format
+	return t
+}
+
+// Execute evaluates the columns for every document and writes the table, a row for every document
+func (t *Table) Execute(wr io.Writer, documents []interface{}) error {
+	rows, err := t.rows(documents)
+	if err != nil {
+		return err
+	}
+	var buffer bytes.Buffer
+	switch t.format {
+	case TableText:
+		err = t.writeText(&buffer, rows)
+	case TableCSV:
+		err = t.writeSeparated(&buffer, rows, ',')
+	case TableTSV:
+		err = t.writeSeparated(&buffer, rows, '\t')
+	case TableMarkdown:
+		t.writeMarkdown(&buffer, rows)
+	case TableJSON:
+		err = t.writeJSON(&buffer, rows)
+	default:
+		return fmt.Errorf("unknown table format %d", t.format)
+	}
+	if err != nil {
+		return fmt.Errorf("can't write the table as %s: %v", t.format, err)
+	}
+	_, err = wr.Write(buffer.Bytes())
+	return err
+}
+
+// rows evaluates the columns for every document, the text of a cell is written the same as by Execute
+func (t *Table) rows(documents []interface{}) ([][]cell, error) {
+	paths := make([]*JSONPath, len(t.columns))
+	for i, column := range t.columns {
+		paths[i] = New(column.Expression).AllowMissingKeys(t.allowMissingKeys)
+		if err := paths[i].Parse(); err != nil {
+			return nil, fmt.Errorf("column %s: %w", column.Header, err)
+		}
+	}
+	rows := make([][]cell, len(documents))
+	for r, document := range documents {
+		rows[r] = make([]cell, len(paths))
+		for c, path := range paths {
+			cell, err := t.cell(path, document)
+			if err != nil {
+				return nil, fmt.Errorf("column %s, row %d: %w", t.columns[c].Header, r, err)
+			}
+			rows[r][c] = cell
+		}
+	}
+	return rows, nil
+}
+
+// cell evaluates the column for the document, the value of a column mixing text with the actions is its text
+func (t *Table) cell(path *JSONPath, document interface{}) (cell, error) {
+	// the ranges consume the parsed nodes, see Execute
+	if err := path.Parse(); err != nil {
+		return cell{}, err
+	}
+	results, literals, err := path.findResults(document)
+	if err != nil {
+		return cell{}, err
+	}
+	var buffer bytes.Buffer
+	var values []interface{}
+	mixed := false
+	for i, result := range results {
+		if literals[i] {
+			mixed = true
+		} else {
+			evaluated, err := path.EvalResults(result)
+			if err != nil {
+				return cell{}, err
+			}
+			values = append(values, evaluated...)
+		}
+		if err := path.printResults(&buffer, result, literals[i]); err != nil {
+			return cell{}, err
+		}
+	}
+	if len(values) == 0 {
+		return cell{text: t.placeholder}, nil
+	}
+	if mixed {
+		values = []interface{}{buffer.String()}
+	}
+	return cell{text: buffer.String(), values: values}, nil
+}
+
+func (t *Table) headers() []string {
+	headers := make([]string, len(t.columns))
+	for i, column := range t.columns {
+		headers[i] = column.Header
+	}
+	return headers
+}
+
+func (t *Table) writeText(wr io.Writer, rows [][]cell) error {
+	writer := tabwriter.NewWriter(wr, 6, 4, 3, ' ', 0)
+	_, _ = fmt.Fprintln(writer, strings.Join(t.headers(), "\t"))
+	for _, row := range rows {
+		texts := make([]string, len(row))
+		for i := range row {
+			texts[i] = row[i].text
+		}
+		_, _ = fmt.Fprintln(writer, strings.Join(texts, "\t"))
+	}
+	return writer.Flush()
+}
+
+func (t *Table) writeSeparated(wr io.Writer, rows [][]cell, comma rune) error {
+	writer := csv.NewWriter(wr)
+	writer.Comma = comma
+	_ = writer.Write(t.headers())
+	for _, row := range rows {
+		texts := make([]string, len(row))
+		for i := range row {
+			texts[i] = row[i].text
+		}
+		_ = writer.Write(texts)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (t *Table) writeMarkdown(wr io.Writer, rows [][]cell) {
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	writeRow := func(texts []string) {
+		for i := range texts {
+			texts[i] = escape.Replace(texts[i])
+		}
+		_, _ = fmt.Fprintf(wr, "| %s |\n", strings.Join(texts, " | "))
+	}
+	writeRow(t.headers())
+	separators := make([]string, len(t.columns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	writeRow(separators)
+	for _, row := range rows {
+		texts := make([]string, len(row))
+		for i := range row {
+			texts[i] = row[i].text
+		}
+		writeRow(texts)
+	}
+}
+
+// writeJSON writes the rows as objects with the keys in order of the columns,
+// a missing value is null and many values are an array
+func (t *Table) writeJSON(wr io.Writer, rows [][]cell) error {
+	var buffer bytes.Buffer
+	buffer.WriteString("[")
+	for r, row := range rows {
+		if r > 0 {
+			buffer.WriteString(",")
+		}
+		buffer.WriteString("{")
+		for c := range row {
+			if c > 0 {
+				buffer.WriteString(",")
+			}
+			if err := encodeJSON(&buffer, t.columns[c].Header, ""); err != nil {
+				return err
+			}
+			buffer.Truncate(buffer.Len() - 1)
+			buffer.WriteString(":")
+
+			var value interface{}
+			switch len(row[c].values) {
+			case 0:
+			case 1:
+				value = row[c].values[0]
+			default:
+				value = row[c].values
+			}
+			if err := encodeJSON(&buffer, value, ""); err != nil {
+				return err
+			}
+			buffer.Truncate(buffer.Len() - 1)
+		}
+		buffer.WriteString("}")
+	}
+	buffer.WriteString("]\n")
+	_, err := wr.Write(buffer.Bytes())
+	return err
+}
@@ -0,0 +1,154 @@
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	var input = []byte(`[
+		{"metadata": {"name": "web", "labels": {"app": "web|front"}}, "age": 3, "ports": [80, 443]},
+		{"metadata": {"name": "db, primary"}, "age": 12.5, "ports": [5432]}
+	]`)
+	var documents []interface{}
+	err := json.Unmarshal(input, &documents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns, err := ParseColumns("NAME:.metadata.name,AGE:{.age},APP:.metadata.labels.app,PORTS:.ports[*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tableTests := []struct {
+		name   string
+		format TableFormat
+		expect string
+	}{
+		{"text", TableText, "" +
+			"NAME          AGE    APP         PORTS\n" +
+			"web           3      web|front   80 443\n" +
+			"db, primary   12.5   <none>      5432\n"},
+		{"csv", TableCSV, "" +
+			"NAME,AGE,APP,PORTS\n" +
+			"web,3,web|front,80 443\n" +
+			"\"db, primary\",12.5,<none>,5432\n"},
+		{"tsv", TableTSV, "" +
+			"NAME\tAGE\tAPP\tPORTS\n" +
+			"web\t3\tweb|front\t80 443\n" +
+			"db, primary\t12.5\t<none>\t5432\n"},
+		{"markdown", TableMarkdown, "" +
+			"| NAME | AGE | APP | PORTS |\n" +
+			"| --- | --- | --- | --- |\n" +
+			"| web | 3 | web\\|front | 80 443 |\n" +
+			"| db, primary | 12.5 | <none> | 5432 |\n"},
+		{"json", TableJSON, `[` +
+			`{"NAME":"web","AGE":3,"APP":"web|front","PORTS":[80,443]},` +
+			`{"NAME":"db, primary","AGE":12.5,"APP":null,"PORTS":5432}` +
+			"]\n"},
+	}
+	for _, test := range tableTests {
+		buf := new(bytes.Buffer)
+		err := NewTable(columns...).AllowMissingKeys(true).Output(test.format).Execute(buf, documents)
+		if err != nil {
+			t.Errorf("in %s, execute error %v", test.name, err)
+			continue
+		}
+		if buf.String() != test.expect {
+			t.Errorf("in %s, expect to get\n%s\ngot\n%s", test.name, test.expect, buf.String())
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	err = NewTable(columns...).AllowMissingKeys(true).Placeholder("-").Output(TableCSV).Execute(buf, documents[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "NAME,AGE,APP,PORTS\n\"db, primary\",12.5,-,5432\n"; buf.String() != expect {
+		t.Errorf("expect to get %q, got %q", expect, buf.String())
+	}
+
+	err = NewTable(columns...).Execute(new(bytes.Buffer), documents)
+	if expect := "column APP, row 1: labels is not found"; err == nil || err.Error() != expect {
+		t.Errorf("expect to get error %q, got %v", expect, err)
+	}
+}
+
+func TestTableMixedColumn(t *testing.T) {
+	documents := []interface{}{
+		map[string]interface{}{"a": "x", "b": []interface{}{1, 2}},
+		map[string]interface{}{"b": []interface{}{3}},
+	}
+	columns, err := ParseColumns("PATH:{.a}/{.b[*]}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the cells are written the same as by Execute
+	for i, expect := range []string{"x/1 2", "/3"} {
+		buf := new(bytes.Buffer)
+		if err := New(columns[0].Expression).AllowMissingKeys(true).Execute(buf, documents[i]); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != expect {
+			t.Errorf("expect to get %q, got %q", expect, buf.String())
+		}
+	}
+
+	tableTests := []struct {
+		format TableFormat
+		expect string
+	}{
+		{TableText, "PATH\nx/1 2\n/3\n"},
+		{TableJSON, `[{"PATH":"x/1 2"},{"PATH":"/3"}]` + "\n"},
+	}
+	for _, test := range tableTests {
+		buf := new(bytes.Buffer)
+		err := NewTable(columns...).AllowMissingKeys(true).Output(test.format).Execute(buf, documents)
+		if err != nil {
+			t.Errorf("in %s, execute error %v", test.format, err)
+			continue
+		}
+		if buf.String() != test.expect {
+			t.Errorf("in %s, expect to get %q, got %q", test.format, test.expect, buf.String())
+		}
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns("NAME:.metadata.name, KEYS:{.data['a', 'b']},TEXT:name {.name}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []Column{
+		{"NAME", "{.metadata.name}"},
+		{"KEYS", "{.data['a', 'b']}"},
+		{"TEXT", "name {.name}"},
+	}
+	if !reflect.DeepEqual(columns, expect) {
+		t.Errorf("expect to get %v, got %v", expect, columns)
+	}
+
+	failColumnsTests := []struct {
+		spec string
+		err  string
+	}{
+		{"", "no column definitions"},
+		{"NAME", `invalid column definition "NAME", expected HEADER:EXPRESSION`},
+		{"NAME:.name,:.age", `invalid column definition ":.age", expected HEADER:EXPRESSION`},
+	}
+	for _, test := range failColumnsTests {
+		_, err := ParseColumns(test.spec)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("in %q, expect to get error %q, got %v", test.spec, test.err, err)
+		}
+	}
+
+	for format, name := range TableFormatName {
+		parsed, err := ParseTableFormat(name)
+		if err != nil || parsed != format {
+			t.Errorf("expect to get %v, got %v, %v", format, parsed, err)
+		}
+	}
+}
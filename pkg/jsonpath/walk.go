@@ -0,0 +1,107 @@
+package jsonpath
+
+// Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses the tree rooted at node in depth-first order,
+// it starts by calling v.Visit(node), the same as go/ast.Walk
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+	for _, child := range children(node) {
+		Walk(v, child)
+	}
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the tree rooted at node in depth-first order,
+// it starts by calling f(node); if f returns true, Inspect invokes f
+// recursively for each of the children of node, followed by a call of f(nil)
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// Rewrite traverses the tree rooted at node in depth-first order and replaces
+// every node with the result of f, the children are rewritten before their parent.
+// A nil result removes the node from its list, and a result that is not a *ListNode,
+// where a *ListNode is required, is wrapped in a new list.
+// The tree is modified in place and the rewritten root is returned.
+func Rewrite(node Node, f func(Node) Node) Node {
+	switch n := node.(type) {
+	case *ListNode:
+		nodes := n.Nodes[:0]
+		for _, child := range n.Nodes {
+			if rewritten := Rewrite(child, f); rewritten != nil {
+				nodes = append(nodes, rewritten)
+			}
+		}
+		n.Nodes = nodes
+	case *FilterNode:
+		n.Left = rewriteList(n.Left, f)
+		n.Right = rewriteList(n.Right, f)
+	case *UnionNode:
+		for i := range n.Nodes {
+			n.Nodes[i] = rewriteList(n.Nodes[i], f)
+		}
+	case *FunctionNode:
+		for i := range n.Args {
+			n.Args[i] = rewriteList(n.Args[i], f)
+		}
+	case *StageNode:
+		for i := range n.Args {
+			n.Args[i] = rewriteList(n.Args[i], f)
+		}
+	}
+	return f(node)
+}
+
+// rewriteList rewrites the list and makes sure the result is a list
+func rewriteList(list *ListNode, f func(Node) Node) *ListNode {
+	result := Rewrite(list, f)
+	if l, ok := result.(*ListNode); ok {
+		return l
+	}
+	l := newList()
+	if result != nil {
+		l.append(result)
+	}
+	return l
+}
+
+// children returns the direct descendants of the node
+func children(node Node) []Node {
+	var nodes []Node
+	switch n := node.(type) {
+	case *ListNode:
+		nodes = append(nodes, n.Nodes...)
+	case *FilterNode:
+		nodes = append(nodes, n.Left, n.Right)
+	case *UnionNode:
+		for _, l := range n.Nodes {
+			nodes = append(nodes, l)
+		}
+	case *FunctionNode:
+		for _, l := range n.Args {
+			nodes = append(nodes, l)
+		}
+	case *StageNode:
+		for _, l := range n.Args {
+			nodes = append(nodes, l)
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,66 @@
+package jsonpath
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	parser, err := Parse("inspect", `{.items[?(@.price > avg(@.prices))].name}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []NodeType
+	Inspect(parser.Root, func(node Node) bool {
+		if node != nil {
+			types = append(types, node.Type())
+		}
+		return true
+	})
+	expect := []NodeType{NodeList, NodeList, NodeField, NodeFilter, NodeList, NodeField,
+		NodeList, NodeFunction, NodeList, NodeField, NodeField}
+	if !reflect.DeepEqual(types, expect) {
+		t.Errorf("expect to visit %v, got %v", expect, types)
+	}
+
+	var fields []string
+	Inspect(parser.Root, func(node Node) bool {
+		if field, ok := node.(*FieldNode); ok {
+			fields = append(fields, field.Value)
+		}
+		// skip the filters
+		_, ok := node.(*FilterNode)
+		return !ok
+	})
+	if !reflect.DeepEqual(fields, []string{"items", "name"}) {
+		t.Errorf("expect to visit %v, got %v", []string{"items", "name"}, fields)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	parser, err := Parse("rewrite", `{.spec.items[?(@.spec.name == "a")].spec}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Rewrite(parser.Root, func(node Node) Node {
+		if field, ok := node.(*FieldNode); ok && field.Value == "spec" {
+			return newField("status")
+		}
+		return node
+	})
+	expect := `{.status.items[?(@.status.name == "a")].status}`
+	if out := Format(parser.Root); out != expect {
+		t.Errorf("expect to get %s, got %s", expect, out)
+	}
+
+	Rewrite(parser.Root, func(node Node) Node {
+		if _, ok := node.(*FilterNode); ok {
+			return nil
+		}
+		return node
+	})
+	expect = `{.status.items.status}`
+	if out := Format(parser.Root); out != expect {
+		t.Errorf("expect to get %s, got %s", expect, out)
+	}
+}
@@ -0,0 +1,219 @@
+package jsonpath
+
+import (
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NodeResult is a value matched in a YAML node tree, with the position of its source
+type NodeResult struct {
+	Node   *yaml.Node  // the matched node, or the node the value was computed from, e.g. by a function
+	Value  interface{} // the decoded value
+	Line   int         // the line of the node in the source, starting from 1
+	Column int         // the column of the node in the source, starting from 1
+}
+
+// nodeItem is a node from the tree, or a value computed from a node
+type nodeItem struct {
+	node  *yaml.Node
+	value reflect.Value // valid only for the computed values
+}
+
+// FindNodes evaluates the expression against the YAML node tree, e.g. loaded with yaml.ToNode,
+// and returns the matches with their line and column in the source.
+// The expression must be a single path, e.g. {.spec.containers[*].image}.
+// Values computed by functions or literals are reported at the node they were computed from.
+func (j *JSONPath) FindNodes(root *yaml.Node) ([]NodeResult, error) {
+	steps, err := j.path()
+	if err != nil {
+		return nil, err
+	}
+
+	// the steps are evaluated node by node, the missing keys are reported for all the nodes at once
+	memory := *j
+	memory.allowMissingKeys = true
+
+	items := []nodeItem{{node: root}}
+	for _, step := range steps {
+		var results []nodeItem
+		for _, item := range items {
+			evaluated, err := memory.evalNode(item, step)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, evaluated...)
+		}
+		if field, ok := step.(*FieldNode); ok && len(results) == 0 && len(items) > 0 && !j.allowMissingKeys {
+			return nil, fmt.Errorf("%s is not found", field.Value)
+		}
+		items = results
+	}
+
+	matches := make([]NodeResult, len(items))
+	for i, item := range items {
+		node := resolveNode(item.node)
+		var value interface{}
+		if item.value.IsValid() {
+			value, err = j.EvalToInterface(item.value)
+		} else {
+			err = node.Decode(&value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		matches[i] = NodeResult{Node: node, Value: value, Line: node.Line, Column: node.Column}
+	}
+	return matches, nil
+}
+
+// evalNode evaluates the step against a single item,
+// the steps that do not select from the node tree are evaluated in memory on the decoded value
+func (j *JSONPath) evalNode(item nodeItem, step Node) ([]nodeItem, error) {
+	node := resolveNode(item.node)
+	if item.value.IsValid() || !isNodeStep(step) {
+		value := item.value
+		if !value.IsValid() {
+			var decoded interface{}
+			if err := node.Decode(&decoded); err != nil {
+				return nil, err
+			}
+			value = reflect.ValueOf(decoded)
+		}
+		values, err := j.walk([]reflect.Value{value}, step)
+		if err != nil {
+			return nil, err
+		}
+		items := make([]nodeItem, len(values))
+		for i := range values {
+			items[i] = nodeItem{node: node, value: values[i]}
+		}
+		return items, nil
+	}
+
+	var nodes []*yaml.Node
+	switch step := step.(type) {
+	case *ListNode:
+		items := []nodeItem{item}
+		for _, next := range step.Nodes {
+			var results []nodeItem
+			for _, item := range items {
+				evaluated, err := j.evalNode(item, next)
+				if err != nil {
+					return nil, err
+				}
+				results = append(results, evaluated...)
+			}
+			items = results
+		}
+		return items, nil
+	case *FieldNode:
+		if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == step.Value {
+					nodes = append(nodes, node.Content[i+1])
+				}
+			}
+		}
+	case *WildcardNode:
+		nodes = nodeChildren(node)
+	case *RecursiveNode:
+		nodes = nodeDescendants(node)
+	case *ArrayNode:
+		if node.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("node at line %d, column %d is not a sequence", node.Line, node.Column)
+		}
+		indexes, err := arrayIndexes(step.Params, len(node.Content))
+		if err != nil {
+			return nil, fmt.Errorf("%v at line %d, column %d", err, node.Line, node.Column)
+		}
+		for _, i := range indexes {
+			nodes = append(nodes, node.Content[i])
+		}
+	case *UnionNode:
+		var items []nodeItem
+		for _, list := range step.Nodes {
+			evaluated, err := j.evalNode(item, list)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, evaluated...)
+		}
+		return items, nil
+	case *FilterNode:
+		if node.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("node at line %d, column %d is not a sequence and cannot be filtered", node.Line, node.Column)
+		}
+		for _, element := range node.Content {
+			var decoded interface{}
+			if err := element.Decode(&decoded); err != nil {
+				return nil, err
+			}
+			matches, err := j.evalFilter([]reflect.Value{reflect.ValueOf([]interface{}{decoded})}, step)
+			if err != nil {
+				return nil, err
+			}
+			if len(matches) > 0 {
+				nodes = append(nodes, element)
+			}
+		}
+	}
+	items := make([]nodeItem, len(nodes))
+	for i := range nodes {
+		items[i] = nodeItem{node: nodes[i]}
+	}
+	return items, nil
+}
+
+// isNodeStep reports whether the step selects from the node tree
+func isNodeStep(step Node) bool {
+	switch step.(type) {
+	case *ListNode, *FieldNode, *WildcardNode, *RecursiveNode, *ArrayNode, *UnionNode, *FilterNode:
+		return true
+	}
+	return false
+}
+
+// resolveNode returns the content of a document and the target of an alias
+func resolveNode(node *yaml.Node) *yaml.Node {
+	for {
+		switch {
+		case node.Kind == yaml.DocumentNode && len(node.Content) == 1:
+			node = node.Content[0]
+		case node.Kind == yaml.AliasNode && node.Alias != nil:
+			node = node.Alias
+		default:
+			return node
+		}
+	}
+}
+
+// nodeChildren returns the values of a mapping or the elements of a sequence
+func nodeChildren(node *yaml.Node) []*yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		var values []*yaml.Node
+		for i := 1; i < len(node.Content); i += 2 {
+			values = append(values, node.Content[i])
+		}
+		return values
+	case yaml.SequenceNode:
+		return node.Content
+	}
+	return nil
+}
+
+// nodeDescendants returns the node and all the mappings and sequences below it in depth-first order,
+// the same as the recursive descent does for the other values
+func nodeDescendants(node *yaml.Node) []*yaml.Node {
+	nodes := nodeChildren(node)
+	if len(nodes) == 0 {
+		return nil
+	}
+	result := []*yaml.Node{node}
+	for _, child := range nodes {
+		result = append(result, nodeDescendants(resolveNode(child))...)
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestFindNodes(t *testing.T) {
+	var input = `kind: List
+items:
+  - kind: Pod
+    metadata:
+      name: pod1
+      labels: &labels
+        app: web
+    ports: [80, 443]
+  - kind: Service
+    metadata:
+      name: svc1
+      labels: *labels
+    ports: [80]
+`
+	var root yaml.Node
+	err := yaml.Unmarshal([]byte(input), &root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodeTests := []struct {
+		name     string
+		template string
+		expect   string
+		err      string
+	}{
+		{"field", "{.kind}", "List@1:7", ""},
+		{"path", "{.items[*].metadata.name}", "pod1@5:13 svc1@11:13", ""},
+		{"index", "{.items[1].kind}", "Service@9:11", ""},
+		{"negative index", "{.items[-1].ports[0]}", "80@13:13", ""},
+		{"slice", "{.items[0].ports[0:2]}", "80@8:13 443@8:17", ""},
+		{"wildcard", "{.items[0].metadata.*}", "pod1@5:13 map[app:web]@6:15", ""},
+		{"alias", "{.items[1].metadata.labels.app}", "web@7:14", ""},
+		{"recursive", "{..name}", "pod1@5:13 svc1@11:13", ""},
+		{"union", "{.items[0]['kind', 'ports']}", "Pod@3:11 [80 443]@8:12", ""},
+		{"filter", "{.items[?(@.kind == 'Service')].metadata.name}", "svc1@11:13", ""},
+		{"filter alias", "{.items[?(@.metadata.labels.app == 'web')].kind}", "Pod@3:11 Service@9:11", ""},
+		{"function", "{.items[*].ports.length()}", "2@8:12 1@13:12", ""},
+		{"function argument", "{length(@.items)}", "2@1:1", ""},
+		{"after function", "{.items[0].metadata.labels.keys()[0]}", "app@6:15", ""},
+		{"missing key", "{.items[*].status}", "", "status is not found"},
+		{"not a sequence", "{.kind[0]}", "", "node at line 1, column 7 is not a sequence"},
+		{"out of bounds", "{.items[2]}", "", "array index out of bounds: index 2, length 2 at line 3, column 3"},
+		{"not a path", "{.kind} {.items}", "", "{.kind} {.items} is not a single path expression"},
+	}
+	for _, test := range nodeTests {
+		results, err := New(test.template).FindNodes(&root)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("in %s, expect to get error %q, got %v", test.name, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("in %s, find error %v", test.name, err)
+			continue
+		}
+		var out []string
+		for _, result := range results {
+			out = append(out, fmt.Sprintf("%v@%d:%d", result.Value, result.Line, result.Column))
+		}
+		if strings.Join(out, " ") != test.expect {
+			t.Errorf("in %s, expect to get %q, got %q", test.name, test.expect, strings.Join(out, " "))
+		}
+	}
+
+	results, err := New("{.items[*].status}").AllowMissingKeys(true).FindNodes(&root)
+	if err != nil || len(results) != 0 {
+		t.Errorf("expect to get no results, got %v, %v", results, err)
+	}
+}
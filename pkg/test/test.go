@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/VirtusLab/go-extended/pkg/cli"
+	"github.com/VirtusLab/go-extended/pkg/errors"
 	"github.com/VirtusLab/go-extended/pkg/log"
 )
 
@@ -147,6 +148,38 @@ func (ctx *testContext) Cleanup() {
 	ctx.cancel()
 }
 
+// Go runs fn in a new goroutine of the test, a panic in fn is converted to an error, see errors.SafeGo.
+// An error fails the test of ctx, or the context cleanup if there is no test.
+// The context cleanup waits for fn to return.
+func Go(ctx Context, fn func() error) {
+	var errorf func(format string, args ...interface{})
+	if ctx.T() != nil {
+		errorf = ctx.T().Errorf
+	}
+	goReporting(ctx, fn, errorf)
+}
+
+// goReporting runs fn like Go, reporting an error with errorf, or returning it from the cleanup if errorf is nil
+func goReporting(ctx Context, fn func() error, errorf func(format string, args ...interface{})) {
+	result := errors.SafeGo(fn)
+	done := make(chan struct{})
+	var failure error
+	go func() {
+		defer close(done)
+		if err := <-result; err != nil {
+			if errorf != nil {
+				errorf("a test goroutine failed with error: %+v", err)
+			} else {
+				failure = err
+			}
+		}
+	}()
+	ctx.AddCleanup(func() error {
+		<-done
+		return failure
+	})
+}
+
 // ProjectRootFlag is the name of the project root directory command line flag
 const ProjectRootFlag = "root"
 
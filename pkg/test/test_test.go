@@ -1,11 +1,74 @@
 package test
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestMain(m *testing.M) {
 	Main(m)
 	os.Exit(0)
 }
+
+func TestGo(t *testing.T) {
+	Run(t,
+		Test{
+			Name: "error without a test",
+			Fn: func(tt Test) {
+				ctx := NewContext(context.Background(), nil).(*testContext)
+				release := make(chan struct{})
+				Go(ctx, func() error {
+					<-release
+					return io.EOF
+				})
+				assert.Len(t, ctx.cleanupFns, 1, tt.Name)
+
+				cleaned := make(chan error, 1)
+				go func() { cleaned <- ctx.cleanupFns[0]() }()
+				select {
+				case err := <-cleaned:
+					t.Fatalf("%s: the cleanup returned %v before the goroutine", tt.Name, err)
+				case <-time.After(10 * time.Millisecond):
+				}
+				close(release)
+				assert.Equal(t, io.EOF, <-cleaned, tt.Name)
+			},
+		},
+		Test{
+			Name: "panic reported on the test",
+			Fn: func(tt Test) {
+				ctx := NewContext(context.Background(), nil).(*testContext)
+				var reports []string
+				goReporting(ctx, func() error {
+					panic("boom")
+				}, func(format string, args ...interface{}) {
+					reports = append(reports, fmt.Sprintf(format, args...))
+				})
+				assert.NoError(t, ctx.cleanupFns[0](), tt.Name)
+				if assert.Len(t, reports, 1, tt.Name) {
+					assert.True(t, strings.HasPrefix(reports[0], "a test goroutine failed with error: panic: boom"), reports[0])
+				}
+			},
+		},
+		Test{
+			Name: "success",
+			Fn: func(tt Test) {
+				ctx := NewContext(context.Background(), nil).(*testContext)
+				ran := false
+				Go(ctx, func() error {
+					ran = true
+					return nil
+				})
+				assert.NoError(t, ctx.cleanupFns[0](), tt.Name)
+				assert.True(t, ran, tt.Name)
+			},
+		},
+	)
+}
@@ -0,0 +1,238 @@
+package time
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and waits, use it instead of the time package functions
+// to make the time based code testable with a FakeClock
+type Clock interface {
+	// Now returns the current time, see time.Now
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time on the returned channel, see time.After
+	After(d time.Duration) <-chan time.Time
+	// Sleep pauses the current goroutine for at least the duration d, see time.Sleep
+	Sleep(d time.Duration)
+	// NewTimer creates a new Timer that will send the current time on its channel after at least duration d,
+	// see time.NewTimer
+	NewTimer(d time.Duration) Timer
+	// NewTicker returns a new Ticker sending the time on its channel with a period specified by the duration,
+	// see time.NewTicker
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer represents a single event, see time.Timer
+type Timer interface {
+	// C returns the channel on which the time is delivered
+	C() <-chan time.Time
+	// Stop prevents the Timer from firing, it returns false if the timer has already expired or been stopped
+	Stop() bool
+	// Reset changes the timer to expire after duration d, it returns true if the timer had been active
+	Reset(d time.Duration) bool
+}
+
+// Ticker delivers ticks at intervals, see time.Ticker
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered
+	C() <-chan time.Time
+	// Stop turns off the ticker, no more ticks will be sent
+	Stop()
+}
+
+type realClock struct{}
+
+// Real returns the clock of the time package
+func Real() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) NewTimer(d time.Duration) Timer         { return realTimer{time.NewTimer(d)} }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// FakeClock is a Clock for tests, its time is moved only by Advance and Set.
+// The timers and tickers fire when the time is moved past their time, as many times as they would have.
+// Like the real ones, they drop the ticks for slow receivers. It is safe for concurrent use.
+type FakeClock struct {
+	mutex   sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+type fakeWaiter struct {
+	clock  *FakeClock
+	at     time.Time
+	period time.Duration
+	c      chan time.Time
+}
+
+// NewFakeClock creates a new fake clock set to the given time
+func NewFakeClock(now time.Time) *FakeClock {
+	f := &FakeClock{now: now}
+	f.changed = sync.NewCond(&f.mutex)
+	return f
+}
+
+// Now returns the current time of the clock
+func (f *FakeClock) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.now
+}
+
+// After returns a channel receiving the time when the clock is moved by at least d, immediately if d is not positive
+func (f *FakeClock) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// Sleep blocks until the clock is moved by at least d, it returns immediately if d is not positive
+func (f *FakeClock) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// NewTimer creates a new timer firing when the clock is moved by at least d, immediately if d is not positive
+func (f *FakeClock) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{clock: f, c: make(chan time.Time, 1)}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.schedule(w, d)
+	return w
+}
+
+// NewTicker creates a new ticker firing every time the clock is moved by another d, d must be greater than zero
+func (f *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	w := &fakeWaiter{clock: f, period: d, c: make(chan time.Time, 1)}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.schedule(w, d)
+	return fakeTicker{w}
+}
+
+// Advance moves the clock by d, firing the timers and tickers on the way
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.moveTo(f.now.Add(d))
+}
+
+// Set moves the clock to t, firing the timers and tickers on the way, the clock never moves backwards
+func (f *FakeClock) Set(t time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.moveTo(t)
+}
+
+// Waiters returns the number of the active timers, tickers and sleeping goroutines
+func (f *FakeClock) Waiters() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil blocks until there are at least n active timers, tickers and sleeping goroutines,
+// use it to wait for the tested goroutines before moving the clock
+func (f *FakeClock) BlockUntil(n int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for len(f.waiters) < n {
+		f.changed.Wait()
+	}
+}
+
+func (f *FakeClock) moveTo(target time.Time) {
+	for len(f.waiters) > 0 && !f.waiters[0].at.After(target) {
+		w := f.waiters[0]
+		f.now = w.at
+		select {
+		case w.c <- f.now:
+		default:
+		}
+		f.remove(w)
+		if w.period > 0 {
+			// the ticks up to the target would be dropped, the receiver has not been woken up yet
+			w.at = w.at.Add(w.period * (target.Sub(w.at)/w.period + 1))
+			f.insert(w)
+		}
+	}
+	if target.After(f.now) {
+		f.now = target
+	}
+}
+
+// schedule adds the waiter firing after d, a timer due already fires immediately, the same as time.NewTimer(0)
+func (f *FakeClock) schedule(w *fakeWaiter, d time.Duration) {
+	w.at = f.now.Add(d)
+	if !w.at.After(f.now) {
+		select {
+		case w.c <- f.now:
+		default:
+		}
+		return
+	}
+	f.insert(w)
+	f.changed.Broadcast()
+}
+
+// insert adds the waiter keeping the waiters sorted by time, after the waiters of the same time
+func (f *FakeClock) insert(w *fakeWaiter) {
+	i := sort.Search(len(f.waiters), func(i int) bool { return f.waiters[i].at.After(w.at) })
+	f.waiters = append(f.waiters, nil)
+	copy(f.waiters[i+1:], f.waiters[i:])
+	f.waiters[i] = w
+}
+
+func (f *FakeClock) remove(w *fakeWaiter) bool {
+	for i, other := range f.waiters {
+		if other == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.c
+}
+
+func (w *fakeWaiter) Stop() bool {
+	w.clock.mutex.Lock()
+	defer w.clock.mutex.Unlock()
+	return w.clock.remove(w)
+}
+
+func (w *fakeWaiter) Reset(d time.Duration) bool {
+	w.clock.mutex.Lock()
+	defer w.clock.mutex.Unlock()
+	active := w.clock.remove(w)
+	w.clock.schedule(w, d)
+	return active
+}
+
+type fakeTicker struct {
+	*fakeWaiter
+}
+
+func (t fakeTicker) Stop() {
+	t.fakeWaiter.Stop()
+}
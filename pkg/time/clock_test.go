@@ -0,0 +1,116 @@
+package time
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestRealClock(t *testing.T) {
+	clock := Real()
+	before := time.Now()
+	assert.False(t, clock.Now().Before(before))
+
+	timer := clock.NewTimer(time.Hour)
+	assert.True(t, timer.Stop())
+	assert.False(t, timer.Stop())
+
+	ticker := clock.NewTicker(time.Millisecond)
+	<-ticker.C()
+	ticker.Stop()
+
+	<-clock.After(time.Millisecond)
+	clock.Sleep(time.Millisecond)
+	assert.True(t, clock.Now().Sub(before) >= 3*time.Millisecond)
+}
+
+func TestFakeClock(t *testing.T) {
+	t.Run("timer", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		timer := clock.NewTimer(time.Second)
+		clock.Advance(999 * time.Millisecond)
+		assert.Len(t, timer.C(), 0)
+		clock.Advance(time.Millisecond)
+		assert.Equal(t, start.Add(time.Second), <-timer.C())
+		assert.False(t, timer.Stop())
+
+		assert.False(t, timer.Reset(time.Second))
+		assert.True(t, timer.Reset(2*time.Second))
+		clock.Advance(time.Second)
+		assert.Len(t, timer.C(), 0)
+		assert.True(t, timer.Stop())
+		clock.Advance(time.Hour)
+		assert.Len(t, timer.C(), 0)
+		assert.Equal(t, 0, clock.Waiters())
+	})
+
+	t.Run("timer due already", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		assert.Equal(t, start, <-clock.NewTimer(0).C())
+		assert.Equal(t, start, <-clock.After(-time.Second))
+		clock.Sleep(0)
+
+		timer := clock.NewTimer(time.Second)
+		assert.True(t, timer.Reset(0))
+		assert.Equal(t, start, <-timer.C())
+		assert.Equal(t, 0, clock.Waiters())
+	})
+
+	t.Run("ticker skipping many periods", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		ticker := clock.NewTicker(time.Nanosecond)
+		defer ticker.Stop()
+		clock.Advance(time.Hour)
+		assert.Equal(t, start.Add(time.Nanosecond), <-ticker.C())
+		clock.Advance(time.Nanosecond)
+		assert.Equal(t, start.Add(time.Hour+time.Nanosecond), <-ticker.C())
+
+		clock.Advance(10 * time.Nanosecond)
+		assert.Equal(t, start.Add(time.Hour+2*time.Nanosecond), <-ticker.C(), "the first tick is kept")
+	})
+
+	t.Run("ticker", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		ticker := clock.NewTicker(time.Second)
+		clock.Advance(time.Second)
+		assert.Equal(t, start.Add(time.Second), <-ticker.C())
+		clock.Advance(3 * time.Second)
+		assert.Equal(t, start.Add(2*time.Second), <-ticker.C(), "the ticks for a slow receiver are dropped")
+		assert.Len(t, ticker.C(), 0)
+		assert.Equal(t, start.Add(4*time.Second), clock.Now())
+
+		ticker.Stop()
+		clock.Advance(time.Hour)
+		assert.Len(t, ticker.C(), 0)
+		assert.Panics(t, func() { clock.NewTicker(0) })
+	})
+
+	t.Run("in order", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		late := clock.After(2 * time.Second)
+		ticker := clock.NewTicker(time.Second)
+		early := clock.After(time.Second)
+		clock.Set(start.Add(2 * time.Second))
+		assert.Equal(t, start.Add(time.Second), <-early)
+		assert.Equal(t, start.Add(time.Second), <-ticker.C())
+		assert.Equal(t, start.Add(2*time.Second), <-late)
+
+		clock.Set(start)
+		assert.Equal(t, start.Add(2*time.Second), clock.Now(), "the clock never moves backwards")
+	})
+
+	t.Run("sleep", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		woken := make(chan time.Time)
+		go func() {
+			clock.Sleep(time.Minute)
+			woken <- clock.Now()
+		}()
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+		assert.Equal(t, start.Add(time.Minute), <-woken)
+	})
+}
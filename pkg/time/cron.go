@@ -0,0 +1,187 @@
+package time
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VirtusLab/go-extended/pkg/errors"
+)
+
+// Cron is a Schedule parsed from a cron expression, see ParseCron
+type Cron struct {
+	expr                                   string
+	minutes, hours, days, months, weekdays uint64
+	anyDay, anyWeekday                     bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	dayField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	weekdayField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a cron expression of five fields: minute, hour, day of month, month and day of week.
+// A field is a *, a value, a range like 1-5, or a list of them like 1,15, optionally with a step like */15.
+// The months and the days of week can be given by the first three letters of their English names,
+// Sunday is both 0 and 7. If both days are restricted, a day matching either of them matches, like in cron.
+// The descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are supported too.
+func ParseCron(expr string) (*Cron, error) {
+	spec := strings.TrimSpace(expr)
+	if descriptor, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = descriptor
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.InvalidArgument.Errorf("invalid cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	c := &Cron{
+		expr:       expr,
+		anyDay:     strings.HasPrefix(fields[2], "*"),
+		anyWeekday: strings.HasPrefix(fields[4], "*"),
+	}
+	for i, target := range []struct {
+		field cronField
+		bits  *uint64
+	}{
+		{minuteField, &c.minutes},
+		{hourField, &c.hours},
+		{dayField, &c.days},
+		{monthField, &c.months},
+		{weekdayField, &c.weekdays},
+	} {
+		bits, err := target.field.parse(fields[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+		}
+		*target.bits = bits
+	}
+	if c.weekdays&(1<<7) != 0 {
+		c.weekdays |= 1
+	}
+	return c, nil
+}
+
+// String returns the cron expression
+func (c *Cron) String() string {
+	return c.expr
+}
+
+// Next returns the first minute matching the expression after t, in the location of t,
+// or the zero time if there is none in the next five years, e.g. for 30 February
+func (c *Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+	limit := t.Year() + 5
+	for t.Year() <= limit {
+		switch {
+		case c.months&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case c.hours&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case c.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	day := c.days&(1<<uint(t.Day())) != 0
+	weekday := c.weekdays&(1<<uint(t.Weekday())) != 0
+	if c.anyDay || c.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+// parse returns the bits of the values matching the field
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		values, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			values = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step < 1 {
+				return 0, errors.InvalidArgument.Errorf("%s: invalid step in %q", f.name, part)
+			}
+		}
+
+		var from, to int
+		switch {
+		case values == "*":
+			from, to = f.min, f.max
+			if f.max == 7 {
+				// 7 is only an alias of Sunday
+				to = 6
+			}
+		case strings.Contains(values, "-"):
+			i := strings.Index(values, "-")
+			var err error
+			if from, err = f.value(values[:i]); err != nil {
+				return 0, err
+			}
+			if to, err = f.value(values[i+1:]); err != nil {
+				return 0, err
+			}
+			if from > to {
+				return 0, errors.InvalidArgument.Errorf("%s: invalid range %q", f.name, values)
+			}
+		default:
+			var err error
+			if from, err = f.value(values); err != nil {
+				return 0, err
+			}
+			to = from
+			if step > 1 {
+				to = f.max
+			}
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value parses a single value of the field, a number or a name
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, errors.InvalidArgument.Errorf("%s: invalid value %q, expected %d-%d", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
@@ -0,0 +1,144 @@
+package time
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/VirtusLab/go-extended/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCronNext(t *testing.T) {
+	// 2020-01-01 is a Wednesday
+	tests := []struct {
+		expr string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			expr: "* * * * *",
+			from: start.Add(30 * time.Second),
+			want: []time.Time{start.Add(time.Minute), start.Add(2 * time.Minute)},
+		},
+		{
+			expr: "*/15 9-17 * * mon-fri",
+			from: start.Add(17*time.Hour + 50*time.Minute),
+			want: []time.Time{
+				time.Date(2020, 1, 2, 9, 0, 0, 0, time.UTC),
+				time.Date(2020, 1, 2, 9, 15, 0, 0, time.UTC),
+			},
+		},
+		{
+			expr: "30 4 1,15 * 5",
+			from: start,
+			want: []time.Time{
+				time.Date(2020, 1, 1, 4, 30, 0, 0, time.UTC),
+				time.Date(2020, 1, 3, 4, 30, 0, 0, time.UTC),
+				time.Date(2020, 1, 10, 4, 30, 0, 0, time.UTC),
+				time.Date(2020, 1, 15, 4, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			expr: "0 0 29 feb *",
+			from: start,
+			want: []time.Time{
+				time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			expr: "0 12 * * 7",
+			from: start,
+			want: []time.Time{time.Date(2020, 1, 5, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			expr: "5/20 0 * * *",
+			from: start,
+			want: []time.Time{start.Add(5 * time.Minute), start.Add(25 * time.Minute), start.Add(45 * time.Minute)},
+		},
+		{
+			expr: "@monthly",
+			from: start,
+			want: []time.Time{time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			expr: "0 0 30 2 *",
+			from: start,
+			want: []time.Time{{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cron, err := ParseCron(tt.expr)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expr, cron.String())
+			next := tt.from
+			for _, want := range tt.want {
+				next = cron.Next(next)
+				assert.Equal(t, want, next)
+			}
+		})
+	}
+}
+
+func TestCronLocation(t *testing.T) {
+	cron, err := ParseCron("0 9 * * *")
+	assert.NoError(t, err)
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	assert.Equal(t, time.Date(2020, 1, 1, 9, 0, 0, 0, loc), cron.Next(start.In(loc)))
+}
+
+func TestParseCronErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"* * * *", `invalid cron expression "* * * *": expected 5 fields, got 4`},
+		{"60 * * * *", `invalid cron expression "60 * * * *": minute: invalid value "60", expected 0-59`},
+		{"* * 0 * *", `invalid cron expression "* * 0 * *": day of month: invalid value "0", expected 1-31`},
+		{"* * * foo *", `invalid cron expression "* * * foo *": month: invalid value "foo", expected 1-12`},
+		{"* 5-1 * * *", `invalid cron expression "* 5-1 * * *": hour: invalid range "5-1"`},
+		{"*/0 * * * *", `invalid cron expression "*/0 * * * *": minute: invalid step in "*/0"`},
+	}
+	for _, tt := range tests {
+		_, err := ParseCron(tt.expr)
+		assert.EqualError(t, err, tt.want)
+		assert.Equal(t, errors.InvalidArgument, errors.CodeOf(err), tt.expr)
+	}
+}
+
+func TestCronTicker(t *testing.T) {
+	cron, err := ParseCron("0 * * * *")
+	assert.NoError(t, err)
+	clock := NewFakeClock(start.Add(59 * time.Minute))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ticks := TickContext(ctx, NewScheduleTicker(clock, cron))
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Hour), <-ticks)
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	assert.Equal(t, start.Add(2*time.Hour), <-ticks)
+}
+
+func ExampleParseCron() {
+	cron, err := ParseCron("30 8 * * mon-fri")
+	if err != nil {
+		panic(err)
+	}
+	next := time.Date(2020, 1, 3, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		next = cron.Next(next)
+		fmt.Println(next.Format("Mon 2006-01-02 15:04"))
+	}
+	// Output:
+	// Mon 2020-01-06 08:30
+	// Tue 2020-01-07 08:30
+	// Wed 2020-01-08 08:30
+}
@@ -0,0 +1,146 @@
+package time
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// EveryContext sends the time with a period specified by the duration argument, until ctx is done.
+// Then the ticker is stopped and the channel is closed.
+// It drops ticks to make up for slow receivers. The duration d must be greater than zero.
+func EveryContext(ctx context.Context, d time.Duration) <-chan time.Time {
+	return TickContext(ctx, Real().NewTicker(d))
+}
+
+// TickContext forwards the ticks of the ticker until ctx is done, then it stops the ticker and closes the channel.
+// It drops ticks to make up for slow receivers.
+func TickContext(ctx context.Context, ticker Ticker) <-chan time.Time {
+	c := make(chan time.Time, 1)
+	go func() {
+		defer close(c)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case tick := <-ticker.C():
+				select {
+				case c <- tick:
+				default:
+				}
+			}
+		}
+	}()
+	return c
+}
+
+// Schedule tells when to tick
+type Schedule interface {
+	// Next returns the first tick after t, or the zero time if there is none
+	Next(t time.Time) time.Time
+}
+
+// ScheduleFunc is a function used as a Schedule
+type ScheduleFunc func(t time.Time) time.Time
+
+// Next calls the function
+func (f ScheduleFunc) Next(t time.Time) time.Time {
+	return f(t)
+}
+
+// Aligned ticks on the multiples of d since the zero time, e.g. on every full minute or hour.
+// The duration d must be greater than zero; if not, Aligned will panic.
+func Aligned(d time.Duration) Schedule {
+	if d <= 0 {
+		panic("non-positive interval for Aligned")
+	}
+	return ScheduleFunc(func(t time.Time) time.Time {
+		return t.Truncate(d).Add(d)
+	})
+}
+
+// Jitter ticks after a random period between d-jitter*d and d+jitter*d, the jitter is a fraction from 0 to 1.
+// It spreads the ticks of many processes started at the same time.
+// The duration d must be greater than zero; if not, Jitter will panic.
+func Jitter(d time.Duration, jitter float64) Schedule {
+	if d <= 0 {
+		panic("non-positive interval for Jitter")
+	}
+	if jitter < 0 {
+		jitter = 0
+	} else if jitter > 1 {
+		jitter = 1
+	}
+	return ScheduleFunc(func(t time.Time) time.Time {
+		return t.Add(d + time.Duration((2*rand.Float64()-1)*jitter*float64(d)))
+	})
+}
+
+// NewAlignedTicker returns a new Ticker ticking on the multiples of d since the zero time, see Aligned
+func NewAlignedTicker(clock Clock, d time.Duration) Ticker {
+	return NewScheduleTicker(clock, Aligned(d))
+}
+
+// NewJitterTicker returns a new Ticker ticking after random periods around d, see Jitter
+func NewJitterTicker(clock Clock, d time.Duration, jitter float64) Ticker {
+	return NewScheduleTicker(clock, Jitter(d, jitter))
+}
+
+// NewScheduleTicker returns a new Ticker ticking as told by the schedule, e.g. a Cron.
+// It drops ticks to make up for slow receivers, like time.Ticker.
+// Stop the ticker to release the associated resources.
+func NewScheduleTicker(clock Clock, schedule Schedule) Ticker {
+	t := &scheduleTicker{
+		c:    make(chan time.Time, 1),
+		stop: make(chan struct{}),
+	}
+	go t.run(clock, schedule)
+	return t
+}
+
+type scheduleTicker struct {
+	c    chan time.Time
+	stop chan struct{}
+	once sync.Once
+}
+
+func (t *scheduleTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *scheduleTicker) Stop() {
+	t.once.Do(func() { close(t.stop) })
+}
+
+func (t *scheduleTicker) run(clock Clock, schedule Schedule) {
+	now := clock.Now()
+	next := schedule.Next(now)
+	if next.IsZero() {
+		return
+	}
+	timer := clock.NewTimer(next.Sub(now))
+	defer timer.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case tick := <-timer.C():
+			select {
+			case t.c <- tick:
+			default:
+			}
+			// the ticks missed in the meantime are dropped
+			now = clock.Now()
+			if now.Before(tick) {
+				now = tick
+			}
+			next = schedule.Next(now)
+			if next.IsZero() {
+				return
+			}
+			timer.Reset(next.Sub(now))
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package time
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEveryContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := EveryContext(ctx, time.Millisecond)
+	<-ticks
+	<-ticks
+	cancel()
+	for range ticks {
+		// drained until closed
+	}
+}
+
+func TestTickContext(t *testing.T) {
+	clock := NewFakeClock(start)
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := TickContext(ctx, clock.NewTicker(time.Second))
+	clock.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Second), <-ticks)
+	cancel()
+	_, ok := <-ticks
+	assert.False(t, ok, "the channel is closed")
+	assert.Equal(t, 0, clock.Waiters(), "the ticker is stopped")
+}
+
+func TestAlignedTicker(t *testing.T) {
+	clock := NewFakeClock(start.Add(20 * time.Second))
+	ticker := NewAlignedTicker(clock, time.Minute)
+	defer ticker.Stop()
+
+	clock.BlockUntil(1)
+	clock.Advance(39 * time.Second)
+	assert.Len(t, ticker.C(), 0)
+	clock.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Minute), <-ticker.C())
+
+	clock.BlockUntil(1)
+	clock.Advance(150 * time.Second)
+	assert.Equal(t, start.Add(2*time.Minute), <-ticker.C(), "the ticks for a slow receiver are dropped")
+	clock.BlockUntil(1)
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, start.Add(4*time.Minute), <-ticker.C(), "the ticks stay aligned")
+
+	assert.Panics(t, func() { Aligned(0) })
+}
+
+func TestJitter(t *testing.T) {
+	schedule := Jitter(10*time.Second, 0.2)
+	for i := 0; i < 100; i++ {
+		next := schedule.Next(start).Sub(start)
+		assert.True(t, next >= 8*time.Second && next <= 12*time.Second, "got %s", next)
+	}
+	assert.Equal(t, start.Add(time.Second), Jitter(time.Second, -1).Next(start))
+
+	clock := NewFakeClock(start)
+	ticker := NewJitterTicker(clock, time.Second, 0.5)
+	clock.BlockUntil(1)
+	clock.Advance(1500 * time.Millisecond)
+	<-ticker.C()
+	ticker.Stop()
+	ticker.Stop()
+}
+
+func TestScheduleTickerZeroPeriod(t *testing.T) {
+	clock := NewFakeClock(start)
+	calls := 0
+	ticker := NewScheduleTicker(clock, ScheduleFunc(func(t time.Time) time.Time {
+		calls++
+		if calls > 1 {
+			return time.Time{}
+		}
+		return t
+	}))
+	defer ticker.Stop()
+	assert.Equal(t, start, <-ticker.C(), "a tick due already is sent without moving the clock")
+}
+
+func TestScheduleTicker(t *testing.T) {
+	clock := NewFakeClock(start)
+	ticks := 0
+	ticker := NewScheduleTicker(clock, ScheduleFunc(func(t time.Time) time.Time {
+		ticks++
+		if ticks > 2 {
+			return time.Time{}
+		}
+		return t.Add(time.Second)
+	}))
+	for i := 1; i <= 2; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		assert.Equal(t, start.Add(time.Duration(i)*time.Second), <-ticker.C())
+	}
+	clock.Advance(time.Hour)
+	assert.Len(t, ticker.C(), 0, "the schedule has ended")
+	ticker.Stop()
+}
@@ -0,0 +1,317 @@
+package try
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/VirtusLab/go-extended/pkg/errors"
+	time2 "github.com/VirtusLab/go-extended/pkg/time"
+)
+
+// State is a state of a circuit breaker
+type State int
+
+// The circuit breaker states
+const (
+	// StateClosed lets every call through and counts the failures
+	StateClosed State = iota
+	// StateOpen rejects every call until the cool-down time passes
+	StateOpen
+	// StateHalfOpen lets a limited number of probe calls through, to decide whether to close or open again
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// ErrBreakerOpen is used when a circuit breaker rejects a call
+type ErrBreakerOpen struct {
+	text string
+}
+
+func (e *ErrBreakerOpen) Error() string {
+	return e.text
+}
+
+// Code returns errors.Unavailable
+func (e *ErrBreakerOpen) Code() errors.Code {
+	return errors.Unavailable
+}
+
+type bucket struct {
+	start     time.Time
+	successes int
+	failures  int
+}
+
+type stateChange struct {
+	from, to State
+}
+
+// Breaker is a circuit breaker, it stops calling a failing dependency for a while, see Execute.
+// It opens when the failures in the rolling window reach the threshold or the ratio,
+// after the cool-down time it lets a few probe calls through, and closes if they all succeed.
+// It is safe for concurrent use.
+type Breaker struct {
+	mutex sync.Mutex
+
+	failureThreshold int
+	failureRatio     float64
+	minRequests      int
+	window           time.Duration
+	buckets          []bucket
+	coolDown         time.Duration
+	probes           int
+	onStateChange    func(from, to State)
+	clock            time2.Clock
+
+	state          State
+	generation     uint64
+	openedAt       time.Time
+	probesInFlight int
+	probeSuccesses int
+	changes        []stateChange
+}
+
+// NewBreaker creates a new closed circuit breaker, it opens after 5 failures in 10 seconds,
+// and lets one probe call through after 5 seconds
+func NewBreaker() *Breaker {
+	return &Breaker{
+		failureThreshold: 5,
+		window:           10 * time.Second,
+		buckets:          make([]bucket, 10),
+		coolDown:         5 * time.Second,
+		probes:           1,
+		clock:            time2.Real(),
+	}
+}
+
+// FailureThreshold sets the number of failures in the rolling window that opens the breaker, 0 disables it
+func (b *Breaker) FailureThreshold(failures int) *Breaker {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.failureThreshold = failures
+	return b
+}
+
+// FailureRatio sets the ratio of failures to all calls in the rolling window that opens the breaker,
+// if there were at least minRequests calls, 0 disables it
+func (b *Breaker) FailureRatio(ratio float64, minRequests int) *Breaker {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.failureRatio = ratio
+	b.minRequests = minRequests
+	return b
+}
+
+// Window sets the duration of the rolling window and the number of buckets it is split into
+func (b *Breaker) Window(window time.Duration, buckets int) *Breaker {
+	if buckets < 1 {
+		buckets = 1
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.window = window
+	b.buckets = make([]bucket, buckets)
+	return b
+}
+
+// CoolDown sets how long the breaker stays open before letting probe calls through
+func (b *Breaker) CoolDown(coolDown time.Duration) *Breaker {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.coolDown = coolDown
+	return b
+}
+
+// HalfOpenProbes sets how many calls are let through when half-open, the breaker closes if they all succeed
+func (b *Breaker) HalfOpenProbes(probes int) *Breaker {
+	if probes < 1 {
+		probes = 1
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.probes = probes
+	return b
+}
+
+// OnStateChange sets a function called after every state change, it must not block
+func (b *Breaker) OnStateChange(fn func(from, to State)) *Breaker {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.onStateChange = fn
+	return b
+}
+
+// Clock sets the clock telling the current time, e.g. a time.FakeClock to make tests deterministic
+func (b *Breaker) Clock(clock time2.Clock) *Breaker {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.clock = clock
+	return b
+}
+
+// State returns the current state
+func (b *Breaker) State() State {
+	b.mutex.Lock()
+	state := b.currentState(b.clock.Now())
+	b.mutex.Unlock()
+	b.notify()
+	return state
+}
+
+// Execute calls fn if the breaker lets it through and records its result,
+// otherwise it returns an ErrBreakerOpen without calling fn. A panic in fn is recorded as a failure and not recovered.
+func (b *Breaker) Execute(fn func() error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	success := false
+	defer func() { done(success) }()
+	err = fn()
+	success = err == nil
+	return err
+}
+
+// Allow reports whether a call is let through, returning an ErrBreakerOpen if it is not.
+// The returned done function must always be called with the result of the call, also when the call panics,
+// otherwise a half-open breaker keeps counting the call as a probe in flight and never lets another one through.
+func (b *Breaker) Allow() (done func(success bool), err error) {
+	b.mutex.Lock()
+	defer b.notify()
+	defer b.mutex.Unlock()
+
+	switch b.currentState(b.clock.Now()) {
+	case StateOpen:
+		return nil, &ErrBreakerOpen{text: "circuit breaker is open"}
+	case StateHalfOpen:
+		if b.probesInFlight+b.probeSuccesses >= b.probes {
+			return nil, &ErrBreakerOpen{text: "circuit breaker is half-open, too many probe calls"}
+		}
+		b.probesInFlight++
+	}
+
+	generation := b.generation
+	var once sync.Once
+	return func(success bool) {
+		once.Do(func() { b.record(generation, success) })
+	}, nil
+}
+
+func (b *Breaker) record(generation uint64, success bool) {
+	b.mutex.Lock()
+	defer b.notify()
+	defer b.mutex.Unlock()
+
+	now := b.clock.Now()
+	if generation != b.generation {
+		// the state changed since the call was let through
+		return
+	}
+	switch b.state {
+	case StateClosed:
+		current := b.bucket(now)
+		if success {
+			current.successes++
+			return
+		}
+		current.failures++
+		if b.tripped(now) {
+			b.setState(StateOpen, now)
+		}
+	case StateHalfOpen:
+		b.probesInFlight--
+		if !success {
+			b.setState(StateOpen, now)
+			return
+		}
+		b.probeSuccesses++
+		if b.probeSuccesses >= b.probes {
+			b.setState(StateClosed, now)
+		}
+	}
+}
+
+// currentState moves an open breaker to half-open after the cool-down time
+func (b *Breaker) currentState(now time.Time) State {
+	if b.state == StateOpen && !now.Before(b.openedAt.Add(b.coolDown)) {
+		b.setState(StateHalfOpen, now)
+	}
+	return b.state
+}
+
+func (b *Breaker) setState(state State, now time.Time) {
+	if state == b.state {
+		return
+	}
+	b.changes = append(b.changes, stateChange{from: b.state, to: state})
+	b.state = state
+	b.generation++
+	b.probesInFlight = 0
+	b.probeSuccesses = 0
+	switch state {
+	case StateOpen:
+		b.openedAt = now
+	case StateClosed:
+		for i := range b.buckets {
+			b.buckets[i] = bucket{}
+		}
+	}
+}
+
+// notify calls the state change function for the pending changes, it must be called without the lock
+func (b *Breaker) notify() {
+	b.mutex.Lock()
+	changes := b.changes
+	b.changes = nil
+	fn := b.onStateChange
+	b.mutex.Unlock()
+	if fn == nil {
+		return
+	}
+	for _, change := range changes {
+		fn(change.from, change.to)
+	}
+}
+
+// bucket returns the bucket of the rolling window for now, resetting it if it is stale
+func (b *Breaker) bucket(now time.Time) *bucket {
+	width := b.window / time.Duration(len(b.buckets))
+	if width <= 0 {
+		width = 1
+	}
+	start := now.Truncate(width)
+	current := &b.buckets[(start.UnixNano()/int64(width))%int64(len(b.buckets))]
+	if !current.start.Equal(start) {
+		*current = bucket{start: start}
+	}
+	return current
+}
+
+// tripped reports whether the failures in the rolling window reached the threshold or the ratio
+func (b *Breaker) tripped(now time.Time) bool {
+	var successes, failures int
+	for _, bucket := range b.buckets {
+		if now.Sub(bucket.start) < b.window {
+			successes += bucket.successes
+			failures += bucket.failures
+		}
+	}
+	if b.failureThreshold > 0 && failures >= b.failureThreshold {
+		return true
+	}
+	total := successes + failures
+	return b.failureRatio > 0 && total > 0 && total >= b.minRequests &&
+		float64(failures)/float64(total) >= b.failureRatio
+}
@@ -0,0 +1,185 @@
+package try
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/VirtusLab/go-extended/pkg/errors"
+	"github.com/VirtusLab/go-extended/pkg/test"
+	time2 "github.com/VirtusLab/go-extended/pkg/time"
+	"github.com/stretchr/testify/assert"
+)
+
+func fail() error    { return io.EOF }
+func succeed() error { return nil }
+
+func newFakeClock() *time2.FakeClock {
+	return time2.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func TestBreaker(t *testing.T) {
+	test.Run(t,
+		test.Test{
+			Name: "failure threshold",
+			Fn: func(tt test.Test) {
+				clock := newFakeClock()
+				var changes []string
+				b := NewBreaker().FailureThreshold(3).Clock(clock).OnStateChange(func(from, to State) {
+					changes = append(changes, from.String()+" -> "+to.String())
+				})
+
+				assert.Equal(t, io.EOF, b.Execute(fail), tt.Name)
+				assert.NoError(t, b.Execute(succeed), tt.Name)
+				assert.Equal(t, io.EOF, b.Execute(fail), tt.Name)
+				assert.Equal(t, StateClosed, b.State(), tt.Name)
+				assert.Equal(t, io.EOF, b.Execute(fail), tt.Name)
+				assert.Equal(t, StateOpen, b.State(), tt.Name)
+				assert.Equal(t, []string{"closed -> open"}, changes, tt.Name)
+
+				called := false
+				err := b.Execute(func() error {
+					called = true
+					return nil
+				})
+				assert.False(t, called, tt.Name)
+				assert.EqualError(t, err, "circuit breaker is open", tt.Name)
+				assert.Equal(t, errors.Unavailable, errors.CodeOf(err), tt.Name)
+			},
+		},
+		test.Test{
+			Name: "rolling window",
+			Fn: func(tt test.Test) {
+				clock := newFakeClock()
+				b := NewBreaker().FailureThreshold(3).Window(10*time.Second, 10).Clock(clock)
+				for i := 0; i < 5; i++ {
+					_ = b.Execute(fail)
+					clock.Advance(6 * time.Second)
+				}
+				assert.Equal(t, StateClosed, b.State(), tt.Name)
+
+				_ = b.Execute(fail)
+				clock.Advance(time.Second)
+				_ = b.Execute(fail)
+				assert.Equal(t, StateOpen, b.State(), tt.Name)
+			},
+		},
+		test.Test{
+			Name: "failure ratio",
+			Fn: func(tt test.Test) {
+				clock := newFakeClock()
+				b := NewBreaker().FailureThreshold(0).FailureRatio(0.5, 4).Clock(clock)
+				_ = b.Execute(fail)
+				_ = b.Execute(fail)
+				assert.Equal(t, StateClosed, b.State(), "%s: below the minimum requests", tt.Name)
+				_ = b.Execute(succeed)
+				_ = b.Execute(succeed)
+				_ = b.Execute(succeed)
+				assert.Equal(t, StateClosed, b.State(), "%s: below the ratio", tt.Name)
+				_ = b.Execute(fail)
+				assert.Equal(t, StateOpen, b.State(), "%s: at the ratio", tt.Name)
+			},
+		},
+		test.Test{
+			Name: "half-open probes",
+			Fn: func(tt test.Test) {
+				clock := newFakeClock()
+				var changes []string
+				b := NewBreaker().FailureThreshold(1).CoolDown(5 * time.Second).HalfOpenProbes(2).Clock(clock).
+					OnStateChange(func(from, to State) {
+						changes = append(changes, from.String()+" -> "+to.String())
+					})
+				_ = b.Execute(fail)
+				clock.Advance(4 * time.Second)
+				assert.Equal(t, StateOpen, b.State(), tt.Name)
+				clock.Advance(time.Second)
+				assert.Equal(t, StateHalfOpen, b.State(), tt.Name)
+
+				first, err := b.Allow()
+				assert.NoError(t, err, tt.Name)
+				second, err := b.Allow()
+				assert.NoError(t, err, tt.Name)
+				_, err = b.Allow()
+				assert.EqualError(t, err, "circuit breaker is half-open, too many probe calls", tt.Name)
+
+				first(true)
+				first(false) // only the first result counts
+				assert.Equal(t, StateHalfOpen, b.State(), tt.Name)
+				second(true)
+				assert.Equal(t, StateClosed, b.State(), tt.Name)
+				assert.Equal(t, []string{"closed -> open", "open -> half-open", "half-open -> closed"}, changes, tt.Name)
+			},
+		},
+		test.Test{
+			Name: "failed probe",
+			Fn: func(tt test.Test) {
+				clock := newFakeClock()
+				b := NewBreaker().FailureThreshold(1).CoolDown(5 * time.Second).Clock(clock)
+				_ = b.Execute(fail)
+				clock.Advance(5 * time.Second)
+				assert.Equal(t, io.EOF, b.Execute(fail), tt.Name)
+				assert.Equal(t, StateOpen, b.State(), tt.Name)
+				clock.Advance(4 * time.Second)
+				assert.Equal(t, StateOpen, b.State(), "%s: the cool-down starts again", tt.Name)
+				clock.Advance(time.Second)
+				assert.NoError(t, b.Execute(succeed), tt.Name)
+				assert.Equal(t, StateClosed, b.State(), tt.Name)
+			},
+		},
+		test.Test{
+			Name: "panicking probe",
+			Fn: func(tt test.Test) {
+				clock := newFakeClock()
+				b := NewBreaker().FailureThreshold(1).CoolDown(5 * time.Second).Clock(clock)
+				_ = b.Execute(fail)
+				clock.Advance(5 * time.Second)
+				assert.PanicsWithValue(t, "boom", func() {
+					_ = b.Execute(func() error { panic("boom") })
+				}, tt.Name)
+				assert.Equal(t, StateOpen, b.State(), "%s: the panic is a failure", tt.Name)
+				clock.Advance(5 * time.Second)
+				assert.NoError(t, b.Execute(succeed), tt.Name)
+				assert.Equal(t, StateClosed, b.State(), tt.Name)
+			},
+		},
+		test.Test{
+			Name: "stale result",
+			Fn: func(tt test.Test) {
+				clock := newFakeClock()
+				b := NewBreaker().FailureThreshold(1).Clock(clock)
+				done, err := b.Allow()
+				assert.NoError(t, err, tt.Name)
+				_ = b.Execute(fail)
+				clock.Advance(5 * time.Second)
+				assert.NoError(t, b.Execute(succeed), tt.Name)
+				assert.Equal(t, StateClosed, b.State(), tt.Name)
+
+				done(false)
+				assert.Equal(t, StateClosed, b.State(), "%s: the result from before opening is ignored", tt.Name)
+			},
+		},
+		test.Test{
+			Name: "concurrent",
+			Fn: func(tt test.Test) {
+				clock := newFakeClock()
+				b := NewBreaker().FailureThreshold(50).Clock(clock)
+				var wg sync.WaitGroup
+				for i := 0; i < 100; i++ {
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						if i%2 == 0 {
+							_ = b.Execute(fail)
+						} else {
+							_ = b.Execute(succeed)
+						}
+						_ = b.State()
+					}(i)
+				}
+				wg.Wait()
+				assert.Equal(t, StateOpen, b.State(), tt.Name)
+			},
+		},
+	)
+}
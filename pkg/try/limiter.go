@@ -0,0 +1,246 @@
+package try
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/VirtusLab/go-extended/pkg/errors"
+	time2 "github.com/VirtusLab/go-extended/pkg/time"
+)
+
+// Limiter is a token bucket rate limiter, it lets through one event per interval on average,
+// and bursts of up to burst events. It is safe for concurrent use.
+type Limiter struct {
+	mutex sync.Mutex
+
+	every time.Duration
+	burst int
+	clock time2.Clock
+
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter creates a new rate limiter with a full bucket, letting through one event every interval,
+// and bursts of up to burst events
+func NewLimiter(every time.Duration, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		every:  every,
+		burst:  burst,
+		clock:  time2.Real(),
+		tokens: float64(burst),
+	}
+}
+
+// Clock sets the clock telling the current time and waiting, e.g. a time.FakeClock to make tests deterministic
+func (l *Limiter) Clock(clock time2.Clock) *Limiter {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.clock = clock
+	l.last = time.Time{}
+	return l
+}
+
+// Allow reports whether an event may happen now, taking a token if it does
+func (l *Limiter) Allow() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.refill(l.clock.Now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Wait blocks until an event may happen, or ctx is done. It returns an error without waiting
+// if the event can't happen before the deadline of ctx.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.mutex.Lock()
+	now := l.clock.Now()
+	l.refill(now)
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens * float64(l.every))
+	}
+	if deadline, ok := ctx.Deadline(); ok && wait > 0 && deadline.Before(now.Add(wait)) {
+		l.tokens++
+		l.mutex.Unlock()
+		return errors.Wrapf(context.DeadlineExceeded, "rate limiter would wait %s", wait)
+	}
+	clock := l.clock
+	l.mutex.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	timer := clock.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		l.mutex.Lock()
+		l.tokens++
+		l.mutex.Unlock()
+		return ctx.Err()
+	}
+}
+
+// refill adds the tokens for the time elapsed since the last refill, up to the burst
+func (l *Limiter) refill(now time.Time) {
+	if !l.last.IsZero() && now.After(l.last) {
+		if l.every <= 0 {
+			l.tokens = float64(l.burst)
+		} else {
+			l.tokens += float64(now.Sub(l.last)) / float64(l.every)
+		}
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+	if l.last.IsZero() || now.After(l.last) {
+		l.last = now
+	}
+}
+
+type waiter struct {
+	weight int64
+	ready  chan struct{}
+}
+
+// Semaphore is a weighted semaphore, it limits the total weight of the concurrent operations.
+// The waiting operations acquire in order, a heavy one is not starved by the lighter ones.
+// It is safe for concurrent use.
+type Semaphore struct {
+	mutex   sync.Mutex
+	size    int64
+	current int64
+	waiters []*waiter
+}
+
+// NewSemaphore creates a new semaphore with the given total weight
+func NewSemaphore(size int64) *Semaphore {
+	return &Semaphore{size: size}
+}
+
+// Acquire blocks until the weight is acquired, or ctx is done
+func (s *Semaphore) Acquire(ctx context.Context, weight int64) error {
+	s.mutex.Lock()
+	if s.size-s.current >= weight && len(s.waiters) == 0 {
+		s.current += weight
+		s.mutex.Unlock()
+		return nil
+	}
+	if weight > s.size {
+		s.mutex.Unlock()
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	w := &waiter{weight: weight, ready: make(chan struct{})}
+	s.waiters = append(s.waiters, w)
+	s.mutex.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		select {
+		case <-w.ready:
+			// acquired in the meantime
+			return nil
+		default:
+		}
+		for i, other := range s.waiters {
+			if other == w {
+				s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+				break
+			}
+		}
+		s.notify()
+		return ctx.Err()
+	}
+}
+
+// TryAcquire acquires the weight if it is available now, without blocking
+func (s *Semaphore) TryAcquire(weight int64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.size-s.current >= weight && len(s.waiters) == 0 {
+		s.current += weight
+		return true
+	}
+	return false
+}
+
+// Release releases the weight, it panics if more is released than acquired
+func (s *Semaphore) Release(weight int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.current -= weight
+	if s.current < 0 {
+		panic(fmt.Sprintf("semaphore: released %d more than acquired", -s.current))
+	}
+	s.notify()
+}
+
+// notify lets the waiting operations acquire in order, as long as their weight is available
+func (s *Semaphore) notify() {
+	for len(s.waiters) > 0 {
+		w := s.waiters[0]
+		if s.size-s.current < w.weight {
+			return
+		}
+		s.current += w.weight
+		s.waiters = s.waiters[1:]
+		close(w.ready)
+	}
+}
+
+// Pool runs functions in at most the given number of goroutines at once
+type Pool struct {
+	semaphore *Semaphore
+	group     sync.WaitGroup
+	errs      errors.MultiError
+}
+
+// NewPool creates a new pool of the given number of workers
+func NewPool(workers int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Pool{semaphore: NewSemaphore(int64(workers))}
+}
+
+// Go blocks until a worker is free, or ctx is done, and runs fn in it.
+// A panic in fn is converted to an error, see errors.Recover.
+func (p *Pool) Go(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := p.semaphore.Acquire(ctx, 1); err != nil {
+		return err
+	}
+	p.group.Add(1)
+	go func() {
+		defer p.group.Done()
+		defer p.semaphore.Release(1)
+		p.errs.Append(errors.Catch(func() error { return fn(ctx) }))
+	}()
+	return nil
+}
+
+// Wait blocks until every function returns, and returns their errors, see errors.MultiError
+func (p *Pool) Wait() error {
+	p.group.Wait()
+	return p.errs.ErrorOrNil()
+}
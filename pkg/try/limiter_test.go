@@ -0,0 +1,231 @@
+package try
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/VirtusLab/go-extended/pkg/errors"
+	"github.com/VirtusLab/go-extended/pkg/test"
+	time2 "github.com/VirtusLab/go-extended/pkg/time"
+	"github.com/stretchr/testify/assert"
+)
+
+// eventually waits for the condition, for the goroutines of a test to reach a blocking point
+func eventually(t *testing.T, condition func() bool, name string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s: condition not met in time", name)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	test.Run(t,
+		test.Test{
+			Name: "allow with burst",
+			Fn: func(tt test.Test) {
+				clock := newFakeClock()
+				l := NewLimiter(time.Second, 3).Clock(clock)
+				for i := 0; i < 3; i++ {
+					assert.True(t, l.Allow(), "%s: event %d", tt.Name, i)
+				}
+				assert.False(t, l.Allow(), tt.Name)
+				clock.Advance(500 * time.Millisecond)
+				assert.False(t, l.Allow(), tt.Name)
+				clock.Advance(500 * time.Millisecond)
+				assert.True(t, l.Allow(), tt.Name)
+				assert.False(t, l.Allow(), tt.Name)
+
+				clock.Advance(time.Hour)
+				for i := 0; i < 3; i++ {
+					assert.True(t, l.Allow(), "%s: event %d after refill", tt.Name, i)
+				}
+				assert.False(t, l.Allow(), "%s: refill is limited to the burst", tt.Name)
+			},
+		},
+		test.Test{
+			Name: "wait",
+			Fn: func(tt test.Test) {
+				clock := newFakeClock()
+				l := NewLimiter(time.Second, 1).Clock(clock)
+				assert.NoError(t, l.Wait(context.Background()), tt.Name)
+
+				var done int32
+				go func() {
+					assert.NoError(t, l.Wait(context.Background()), tt.Name)
+					atomic.StoreInt32(&done, 1)
+				}()
+				clock.BlockUntil(1)
+				clock.Advance(500 * time.Millisecond)
+				assert.Equal(t, int32(0), atomic.LoadInt32(&done), tt.Name)
+				clock.Advance(500 * time.Millisecond)
+				eventually(t, func() bool { return atomic.LoadInt32(&done) == 1 }, tt.Name)
+				assert.False(t, l.Allow(), "%s: the token was taken by the waiting event", tt.Name)
+			},
+		},
+		test.Test{
+			Name: "wait canceled",
+			Fn: func(tt test.Test) {
+				clock := newFakeClock()
+				l := NewLimiter(time.Second, 1).Clock(clock)
+				assert.True(t, l.Allow(), tt.Name)
+
+				ctx, cancel := context.WithCancel(context.Background())
+				result := make(chan error, 1)
+				go func() { result <- l.Wait(ctx) }()
+				clock.BlockUntil(1)
+				cancel()
+				assert.Equal(t, context.Canceled, <-result, tt.Name)
+
+				clock.Advance(time.Second)
+				assert.True(t, l.Allow(), "%s: the token was given back", tt.Name)
+				assert.Equal(t, context.Canceled, l.Wait(ctx), tt.Name)
+			},
+		},
+		test.Test{
+			Name: "wait past the deadline",
+			Fn: func(tt test.Test) {
+				clock := time2.NewFakeClock(time.Now())
+				l := NewLimiter(time.Hour, 1).Clock(clock)
+				assert.True(t, l.Allow(), tt.Name)
+
+				ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+				defer cancel()
+				err := l.Wait(ctx)
+				assert.EqualError(t, err, "rate limiter would wait 1h0m0s: context deadline exceeded", tt.Name)
+				assert.True(t, errors.Is(err, context.DeadlineExceeded), tt.Name)
+				assert.Equal(t, 0, clock.Waiters(), tt.Name)
+
+				clock.Advance(time.Hour)
+				assert.True(t, l.Allow(), "%s: the token was given back", tt.Name)
+			},
+		},
+	)
+}
+
+func TestSemaphore(t *testing.T) {
+	test.Run(t,
+		test.Test{
+			Name: "try acquire",
+			Fn: func(tt test.Test) {
+				s := NewSemaphore(3)
+				assert.True(t, s.TryAcquire(2), tt.Name)
+				assert.False(t, s.TryAcquire(2), tt.Name)
+				assert.True(t, s.TryAcquire(1), tt.Name)
+				s.Release(3)
+				assert.True(t, s.TryAcquire(3), tt.Name)
+				assert.Panics(t, func() { s.Release(4) }, tt.Name)
+			},
+		},
+		test.Test{
+			Name: "acquire in order",
+			Fn: func(tt test.Test) {
+				s := NewSemaphore(10)
+				assert.NoError(t, s.Acquire(context.Background(), 8), tt.Name)
+
+				acquired := make(chan struct{})
+				go func() {
+					assert.NoError(t, s.Acquire(context.Background(), 5), tt.Name)
+					close(acquired)
+				}()
+				eventually(t, func() bool {
+					s.mutex.Lock()
+					defer s.mutex.Unlock()
+					return len(s.waiters) == 1
+				}, tt.Name)
+				assert.False(t, s.TryAcquire(1), "%s: the waiting heavy operation goes first", tt.Name)
+
+				s.Release(8)
+				<-acquired
+				assert.True(t, s.TryAcquire(5), tt.Name)
+				assert.False(t, s.TryAcquire(1), tt.Name)
+			},
+		},
+		test.Test{
+			Name: "acquire canceled",
+			Fn: func(tt test.Test) {
+				s := NewSemaphore(2)
+				assert.NoError(t, s.Acquire(context.Background(), 2), tt.Name)
+
+				ctx, cancel := context.WithCancel(context.Background())
+				heavy := make(chan error, 1)
+				go func() { heavy <- s.Acquire(ctx, 2) }()
+				eventually(t, func() bool {
+					s.mutex.Lock()
+					defer s.mutex.Unlock()
+					return len(s.waiters) == 1
+				}, tt.Name)
+				light := make(chan error, 1)
+				go func() { light <- s.Acquire(context.Background(), 1) }()
+				eventually(t, func() bool {
+					s.mutex.Lock()
+					defer s.mutex.Unlock()
+					return len(s.waiters) == 2
+				}, tt.Name)
+
+				s.Release(1)
+				cancel()
+				assert.Equal(t, context.Canceled, <-heavy, tt.Name)
+				assert.NoError(t, <-light, "%s: the next operation acquires when the first one gives up", tt.Name)
+
+				ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+				defer cancel()
+				assert.Equal(t, context.DeadlineExceeded, s.Acquire(ctx, 3), "%s: more than the size", tt.Name)
+			},
+		},
+	)
+}
+
+func TestPool(t *testing.T) {
+	p := NewPool(3)
+	var running, maxRunning int32
+	var mutex sync.Mutex
+	for i := 0; i < 12; i++ {
+		i := i
+		err := p.Go(context.Background(), func(context.Context) error {
+			n := atomic.AddInt32(&running, 1)
+			mutex.Lock()
+			if n > maxRunning {
+				maxRunning = n
+			}
+			mutex.Unlock()
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			switch i {
+			case 4:
+				return fmt.Errorf("job %d failed", i)
+			case 7:
+				panic("job 7 panicked")
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+	}
+
+	err := p.Wait()
+	assert.True(t, maxRunning <= 3, "got %d running at once", maxRunning)
+	var multi *errors.MultiError
+	if assert.True(t, errors.As(err, &multi)) {
+		assert.Equal(t, 2, multi.Len())
+	}
+	var panicErr *errors.PanicError
+	assert.True(t, errors.As(err, &panicErr))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	blocked := NewPool(1)
+	release := make(chan struct{})
+	assert.NoError(t, blocked.Go(context.Background(), func(context.Context) error {
+		<-release
+		return nil
+	}))
+	assert.Equal(t, context.Canceled, blocked.Go(ctx, func(context.Context) error { return nil }))
+	close(release)
+	assert.NoError(t, blocked.Wait())
+}
@@ -0,0 +1,222 @@
+package try
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/VirtusLab/go-extended/pkg/errors"
+	time2 "github.com/VirtusLab/go-extended/pkg/time"
+)
+
+// Backoff returns the delay before the given retry, counted from 1, previous is the delay before the previous retry
+type Backoff func(retry int, previous time.Duration) time.Duration
+
+// Constant waits the same delay before every retry
+func Constant(delay time.Duration) Backoff {
+	return func(int, time.Duration) time.Duration {
+		return delay
+	}
+}
+
+// Linear waits the initial delay before the first retry, and increment more before every next one
+func Linear(initial, increment time.Duration) Backoff {
+	return func(retry int, _ time.Duration) time.Duration {
+		return saturate(float64(initial) + float64(increment)*float64(retry-1))
+	}
+}
+
+// Exponential waits the initial delay before the first retry, multiplied by factor before every next one
+func Exponential(initial time.Duration, factor float64) Backoff {
+	return func(retry int, _ time.Duration) time.Duration {
+		return saturate(float64(initial) * math.Pow(factor, float64(retry-1)))
+	}
+}
+
+// DecorrelatedJitter waits a random delay between base and three times the previous delay, at most max,
+// it spreads the retries of many clients better than the exponential backoff
+func DecorrelatedJitter(base, max time.Duration) Backoff {
+	return func(_ int, previous time.Duration) time.Duration {
+		if previous < base {
+			previous = base
+		}
+		upper := saturate(3 * float64(previous))
+		delay := base
+		if upper > base {
+			delay += time.Duration(rand.Int63n(int64(upper - base)))
+		}
+		if delay > max {
+			return max
+		}
+		return delay
+	}
+}
+
+// saturate converts the delay to a duration, limited to the longest duration
+func saturate(delay float64) time.Duration {
+	if delay >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	if delay < 0 {
+		return 0
+	}
+	return time.Duration(delay)
+}
+
+// Option configures Do
+type Option func(*retry)
+
+type retry struct {
+	backoff        Backoff
+	maxDelay       time.Duration
+	maxAttempts    int
+	maxElapsed     time.Duration
+	attemptTimeout time.Duration
+	retryable      func(error) bool
+	clock          time2.Clock
+}
+
+// WithBackoff sets the delays between the attempts, the default is Exponential(100*time.Millisecond, 2)
+func WithBackoff(backoff Backoff) Option {
+	return func(r *retry) {
+		r.backoff = backoff
+	}
+}
+
+// MaxDelay limits every delay between the attempts
+func MaxDelay(delay time.Duration) Option {
+	return func(r *retry) {
+		r.maxDelay = delay
+	}
+}
+
+// MaxAttempts sets how many times at most the function is called, the default is 5, 0 means no limit
+func MaxAttempts(attempts int) Option {
+	return func(r *retry) {
+		r.maxAttempts = attempts
+	}
+}
+
+// MaxElapsed stops retrying if the next attempt would start after the given time since the first one
+func MaxElapsed(elapsed time.Duration) Option {
+	return func(r *retry) {
+		r.maxElapsed = elapsed
+	}
+}
+
+// AttemptTimeout sets a timeout of the context passed to every attempt
+func AttemptTimeout(timeout time.Duration) Option {
+	return func(r *retry) {
+		r.attemptTimeout = timeout
+	}
+}
+
+// RetryIf sets which errors are retried, by default every error is
+func RetryIf(retryable func(err error) bool) Option {
+	return func(r *retry) {
+		r.retryable = retryable
+	}
+}
+
+// WithClock sets the clock measuring the elapsed time and the delays, e.g. a time.FakeClock in tests,
+// the attempt timeout is measured by the context
+func WithClock(clock time2.Clock) Option {
+	return func(r *retry) {
+		r.clock = clock
+	}
+}
+
+// ErrRetry is used when Do gives up, it records the number of attempts and the last error
+type ErrRetry struct {
+	// Attempts is the number of times the function was called
+	Attempts int
+	// Last is the error of the last attempt, or the context error if there was no attempt
+	Last error
+
+	reason string
+	code   errors.Code
+}
+
+func (e *ErrRetry) Error() string {
+	return fmt.Sprintf("%s after %d attempts: %v", e.reason, e.Attempts, e.Last)
+}
+
+// Cause returns the last error
+func (e *ErrRetry) Cause() error {
+	return e.Last
+}
+
+// Unwrap returns the last error
+func (e *ErrRetry) Unwrap() error {
+	return e.Last
+}
+
+// Code returns errors.Timeout if the elapsed time was exceeded, the code of the context error if it was done,
+// or errors.Unknown to use the code of the last error
+func (e *ErrRetry) Code() errors.Code {
+	return e.code
+}
+
+// Do calls fn until it succeeds, waiting between the attempts as set by the options.
+// Do gives up when the error is not retryable, the maximum attempts or elapsed time are reached,
+// or ctx is done, and returns an ErrRetry.
+func Do(ctx context.Context, fn func(ctx context.Context) error, opts ...Option) error {
+	r := &retry{
+		backoff:     Exponential(100*time.Millisecond, 2),
+		maxAttempts: 5,
+		retryable:   func(error) bool { return true },
+		clock:       time2.Real(),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	start := r.clock.Now()
+	var last error
+	var delay time.Duration
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			if last == nil {
+				last = err
+			}
+			return &ErrRetry{Attempts: attempt - 1, Last: last, reason: "context done: " + err.Error(), code: errors.CodeOf(err)}
+		}
+
+		last = r.attempt(ctx, fn)
+		if last == nil {
+			return nil
+		}
+		if !r.retryable(last) {
+			return &ErrRetry{Attempts: attempt, Last: last, reason: "not retryable"}
+		}
+		if r.maxAttempts > 0 && attempt >= r.maxAttempts {
+			return &ErrRetry{Attempts: attempt, Last: last, reason: "max attempts reached"}
+		}
+
+		delay = r.backoff(attempt, delay)
+		if r.maxDelay > 0 && delay > r.maxDelay {
+			delay = r.maxDelay
+		}
+		if r.maxElapsed > 0 && r.clock.Now().Sub(start)+delay > r.maxElapsed {
+			return &ErrRetry{Attempts: attempt, Last: last, reason: "max elapsed time reached", code: errors.Timeout}
+		}
+
+		timer := r.clock.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C():
+		}
+	}
+}
+
+func (r *retry) attempt(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.attemptTimeout <= 0 {
+		return fn(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.attemptTimeout)
+	defer cancel()
+	return fn(ctx)
+}
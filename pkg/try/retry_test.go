@@ -0,0 +1,184 @@
+package try
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/VirtusLab/go-extended/pkg/errors"
+	"github.com/VirtusLab/go-extended/pkg/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	test.Run(t,
+		test.Test{
+			Name: "constant",
+			Fn: func(tt test.Test) {
+				backoff := Constant(time.Second)
+				assert.Equal(t, time.Second, backoff(1, 0), tt.Name)
+				assert.Equal(t, time.Second, backoff(5, time.Second), tt.Name)
+			},
+		},
+		test.Test{
+			Name: "linear",
+			Fn: func(tt test.Test) {
+				backoff := Linear(time.Second, 500*time.Millisecond)
+				assert.Equal(t, time.Second, backoff(1, 0), tt.Name)
+				assert.Equal(t, 2*time.Second, backoff(3, 0), tt.Name)
+			},
+		},
+		test.Test{
+			Name: "exponential",
+			Fn: func(tt test.Test) {
+				backoff := Exponential(100*time.Millisecond, 2)
+				assert.Equal(t, 100*time.Millisecond, backoff(1, 0), tt.Name)
+				assert.Equal(t, 800*time.Millisecond, backoff(4, 0), tt.Name)
+				assert.Equal(t, time.Duration(1<<63-1), backoff(1000, 0), tt.Name)
+			},
+		},
+		test.Test{
+			Name: "decorrelated jitter",
+			Fn: func(tt test.Test) {
+				backoff := DecorrelatedJitter(10*time.Millisecond, time.Second)
+				delay := time.Duration(0)
+				for retry := 1; retry < 100; retry++ {
+					next := backoff(retry, delay)
+					assert.True(t, next >= 10*time.Millisecond, "%s: %s below base", tt.Name, next)
+					assert.True(t, next <= time.Second, "%s: %s above max", tt.Name, next)
+					if delay > 0 {
+						assert.True(t, next <= 3*delay, "%s: %s above three times %s", tt.Name, next, delay)
+					}
+					delay = next
+				}
+			},
+		},
+	)
+}
+
+func TestDo(t *testing.T) {
+	fast := WithBackoff(Constant(time.Millisecond))
+	test.Run(t,
+		test.Test{
+			Name: "success after retries",
+			Fn: func(tt test.Test) {
+				calls := 0
+				err := Do(context.Background(), func(context.Context) error {
+					calls++
+					if calls < 3 {
+						return io.EOF
+					}
+					return nil
+				}, fast)
+				assert.NoError(t, err, tt.Name)
+				assert.Equal(t, 3, calls, tt.Name)
+			},
+		},
+		test.Test{
+			Name: "max attempts",
+			Fn: func(tt test.Test) {
+				calls := 0
+				err := Do(context.Background(), func(context.Context) error {
+					calls++
+					return io.EOF
+				}, fast, MaxAttempts(4))
+				assert.EqualError(t, err, "max attempts reached after 4 attempts: EOF", tt.Name)
+				assert.Equal(t, 4, calls, tt.Name)
+				var retryErr *ErrRetry
+				assert.True(t, errors.As(err, &retryErr), tt.Name)
+				assert.Equal(t, 4, retryErr.Attempts, tt.Name)
+				assert.True(t, errors.Is(err, io.EOF), tt.Name)
+			},
+		},
+		test.Test{
+			Name: "not retryable",
+			Fn: func(tt test.Test) {
+				calls := 0
+				err := Do(context.Background(), func(context.Context) error {
+					calls++
+					if calls == 2 {
+						return errors.InvalidArgument.New("bad request")
+					}
+					return io.EOF
+				}, fast, RetryIf(func(err error) bool {
+					return errors.CodeOf(err) != errors.InvalidArgument
+				}))
+				assert.EqualError(t, err, "not retryable after 2 attempts: bad request", tt.Name)
+				assert.Equal(t, errors.InvalidArgument, errors.CodeOf(err), tt.Name)
+			},
+		},
+		test.Test{
+			Name: "max elapsed",
+			Fn: func(tt test.Test) {
+				clock := newFakeClock()
+				start := clock.Now()
+				var attempts []time.Duration
+				result := make(chan error, 1)
+				go func() {
+					result <- Do(context.Background(), func(context.Context) error {
+						attempts = append(attempts, clock.Now().Sub(start))
+						return io.EOF
+					}, WithBackoff(Constant(10*time.Millisecond)), MaxAttempts(0), MaxElapsed(25*time.Millisecond),
+						WithClock(clock))
+				}()
+				for i := 0; i < 2; i++ {
+					clock.BlockUntil(1)
+					clock.Advance(10 * time.Millisecond)
+				}
+				err := <-result
+				assert.EqualError(t, err, "max elapsed time reached after 3 attempts: EOF", tt.Name)
+				assert.Equal(t, errors.Timeout, errors.CodeOf(err), tt.Name)
+				assert.Equal(t, []time.Duration{0, 10 * time.Millisecond, 20 * time.Millisecond}, attempts, tt.Name)
+			},
+		},
+		test.Test{
+			Name: "max delay",
+			Fn: func(tt test.Test) {
+				start := time.Now()
+				err := Do(context.Background(), func(context.Context) error {
+					return io.EOF
+				}, WithBackoff(Constant(time.Hour)), MaxDelay(time.Millisecond), MaxAttempts(3))
+				assert.Error(t, err, tt.Name)
+				assert.True(t, time.Since(start) < time.Minute, tt.Name)
+			},
+		},
+		test.Test{
+			Name: "context canceled",
+			Fn: func(tt test.Test) {
+				ctx, cancel := context.WithCancel(context.Background())
+				calls := 0
+				err := Do(ctx, func(context.Context) error {
+					calls++
+					cancel()
+					return io.EOF
+				}, WithBackoff(Constant(time.Hour)))
+				assert.EqualError(t, err, "context done: context canceled after 1 attempts: EOF", tt.Name)
+				assert.Equal(t, errors.Canceled, errors.CodeOf(err), tt.Name)
+				assert.Equal(t, 1, calls, tt.Name)
+
+				err = Do(ctx, func(context.Context) error { return nil })
+				assert.EqualError(t, err, "context done: context canceled after 0 attempts: context canceled", tt.Name)
+				assert.True(t, errors.Is(err, context.Canceled), tt.Name)
+			},
+		},
+		test.Test{
+			Name: "attempt timeout",
+			Fn: func(tt test.Test) {
+				calls := 0
+				err := Do(context.Background(), func(ctx context.Context) error {
+					calls++
+					if calls == 1 {
+						<-ctx.Done()
+						return ctx.Err()
+					}
+					_, ok := ctx.Deadline()
+					assert.True(t, ok, tt.Name)
+					return nil
+				}, fast, AttemptTimeout(5*time.Millisecond))
+				assert.NoError(t, err, tt.Name)
+				assert.Equal(t, 2, calls, tt.Name)
+			},
+		},
+	)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/VirtusLab/go-extended/pkg/errors"
 	time2 "github.com/VirtusLab/go-extended/pkg/time"
 )
 
@@ -16,14 +17,26 @@ func (e *ErrTimout) Error() string {
 	return e.text
 }
 
+// Code returns errors.Timeout
+func (e *ErrTimout) Code() errors.Code {
+	return errors.Timeout
+}
+
 // Until keeps trying until timeout or there is a result or an error
 func Until(something func() (bool, error), tick, timeout time.Duration) (bool, error) {
+	return UntilClock(time2.Real(), something, tick, timeout)
+}
+
+// UntilClock is like Until but measures the ticks and the timeout with the given clock
+func UntilClock(clock time2.Clock, something func() (bool, error), tick, timeout time.Duration) (bool, error) {
 	counter := 0
-	tickChan := time2.Every(tick)
-	timeoutChan := time.After(timeout)
+	ticker := clock.NewTicker(tick)
+	defer ticker.Stop()
+	timer := clock.NewTimer(timeout)
+	defer timer.Stop()
 	for {
 		select {
-		case <-tickChan:
+		case <-ticker.C():
 			ok, err := something()
 			if err != nil {
 				return false, err
@@ -31,7 +44,7 @@ func Until(something func() (bool, error), tick, timeout time.Duration) (bool, e
 				return true, nil
 			}
 			counter = counter + 1
-		case <-timeoutChan:
+		case <-timer.C():
 			return false, &ErrTimout{
 				text: fmt.Sprintf("timed out after: %s, tries: %d", timeout, counter),
 			}
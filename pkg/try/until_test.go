@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/VirtusLab/go-extended/pkg/errors"
 	"github.com/VirtusLab/go-extended/pkg/test"
 	"github.com/stretchr/testify/assert"
 )
@@ -37,6 +38,7 @@ func TestUntil(t *testing.T) {
 				ok, err := Until(something, tick, timeout)
 
 				assert.EqualError(t, err, "timed out after: 3ns, tries: 1")
+				assert.Equal(t, errors.Timeout, errors.CodeOf(err))
 				assert.False(t, ok)
 			},
 		},
@@ -64,3 +66,57 @@ func TestUntil(t *testing.T) {
 		},
 	)
 }
+
+func TestUntilClock(t *testing.T) {
+	test.Run(t,
+		test.Test{
+			Name: "timeout",
+			Fn: func(tt test.Test) {
+				clock := newFakeClock()
+				calls := make(chan struct{})
+				result := make(chan error, 1)
+				go func() {
+					_, err := UntilClock(clock, func() (bool, error) {
+						calls <- struct{}{}
+						return false, nil
+					}, time.Second, 2500*time.Millisecond)
+					result <- err
+				}()
+				clock.BlockUntil(2)
+				for i := 0; i < 2; i++ {
+					clock.Advance(time.Second)
+					<-calls
+				}
+				clock.Advance(500 * time.Millisecond)
+				assert.EqualError(t, <-result, "timed out after: 2.5s, tries: 2", tt.Name)
+				assert.Equal(t, 0, clock.Waiters(), "%s: the ticker and the timer are stopped", tt.Name)
+			},
+		},
+		test.Test{
+			Name: "retried",
+			Fn: func(tt test.Test) {
+				clock := newFakeClock()
+				calls := make(chan int)
+				result := make(chan bool, 1)
+				go func() {
+					counter := 0
+					ok, err := UntilClock(clock, func() (bool, error) {
+						counter++
+						calls <- counter
+						return counter == 3, nil
+					}, time.Second, time.Minute)
+					assert.NoError(t, err, tt.Name)
+					result <- ok
+				}()
+				clock.BlockUntil(2)
+				counter := 0
+				for counter < 3 {
+					clock.Advance(time.Second)
+					counter = <-calls
+				}
+				assert.True(t, <-result, tt.Name)
+				assert.Equal(t, 3, counter, tt.Name)
+			},
+		},
+	)
+}
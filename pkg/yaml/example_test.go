@@ -70,3 +70,64 @@ data:
 	// []interface {}
 	// slice
 }
+
+func ExampleToNode() {
+	y := `---
+welcome:
+  message:
+  - "Good Morning"
+  - "Hello World!"
+`
+
+	node, err := yaml.ToNode(strings.NewReader(y))
+	if err != nil {
+		_, _ = fmt.Fprint(os.Stderr, err)
+	}
+	message := node.Content[0].Content[1].Content[1]
+	fmt.Println(message.Content[1].Value)
+	fmt.Println(message.Content[1].Line, message.Content[1].Column)
+
+	// Output:
+	// Hello World!
+	// 5 5
+}
+
+func ExampleToNodes() {
+	y := `name: first
+---
+name: second
+`
+
+	nodes, err := yaml.ToNodes(strings.NewReader(y))
+	if err != nil {
+		_, _ = fmt.Fprint(os.Stderr, err)
+	}
+	for _, node := range nodes {
+		name := node.Content[0].Content[1]
+		fmt.Println(name.Value, name.Line)
+	}
+
+	// Output:
+	// first 1
+	// second 3
+}
+
+func ExampleToInterfaces() {
+	y := `name: first
+---
+- second
+- third
+`
+
+	documents, err := yaml.ToInterfaces(strings.NewReader(y))
+	if err != nil {
+		_, _ = fmt.Fprint(os.Stderr, err)
+	}
+	for _, document := range documents {
+		fmt.Println(document)
+	}
+
+	// Output:
+	// map[name:first]
+	// [second third]
+}
@@ -1,6 +1,7 @@
 package yaml
 
 import (
+	"fmt"
 	"io"
 
 	"gopkg.in/yaml.v3"
@@ -30,3 +31,58 @@ func ToInterface(reader io.Reader) (interface{}, error) {
 	}
 	return result, nil
 }
+
+// ToInterfaces unmarshalls all YAML documents from the reader to "generic" interfaces
+func ToInterfaces(reader io.Reader) ([]interface{}, error) {
+	var values []interface{}
+	decoder := yaml.NewDecoder(reader)
+	for {
+		var value interface{}
+		err := decoder.Decode(&value)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// ToNodes unmarshalls all YAML documents from the reader to node trees,
+// the nodes keep the line and column of their source, see yaml.Node
+func ToNodes(reader io.Reader) ([]*yaml.Node, error) {
+	var nodes []*yaml.Node
+	decoder := yaml.NewDecoder(reader)
+	for {
+		node := &yaml.Node{}
+		err := decoder.Decode(node)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// ToNode unmarshalls a single YAML document from the reader to a node tree,
+// an empty reader results in a document with an empty mapping
+func ToNode(reader io.Reader) (*yaml.Node, error) {
+	nodes, err := ToNodes(reader)
+	if err != nil {
+		return nil, err
+	}
+	switch len(nodes) {
+	case 0:
+		return &yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		}, nil
+	case 1:
+		return nodes[0], nil
+	default:
+		return nil, fmt.Errorf("expected a single YAML document, got %d", len(nodes))
+	}
+}